@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// Terminals can deliver several keystrokes in one key event when the
+	// program lags, so only longer bursts are checked
+	maxBurstRunes  = 3
+	maxBurstWPM    = 300                  // Faster than any human sustains, even in a burst
+	minKeyInterval = 8 * time.Millisecond // Keystrokes closer together than this are suspicious
+	maxFastKeys    = 10                   // Consecutive suspicious keystrokes before flagging
+)
+
+// screenKeystroke inspects a typing keystroke for pastes and inhumanly fast
+// input. Suspicious rounds are flagged so their results are never recorded.
+// It returns true if the keystroke should be dropped.
+func (m *model) screenKeystroke(msg tea.KeyMsg) bool {
+	now := time.Now()
+	defer func() { m.lastKeyTime = now }()
+
+	if msg.Paste {
+		m.flag("paste detected")
+		return true
+	}
+	if len(msg.Runes) > maxBurstRunes && m.matchesTarget(msg.Runes) &&
+		burstWPM(len(msg.Runes), now.Sub(m.lastKeyTime)) > maxBurstWPM {
+		m.flag("input burst detected")
+		return true
+	}
+
+	if !m.lastKeyTime.IsZero() && now.Sub(m.lastKeyTime) < minKeyInterval {
+		m.fastKeys++
+		if m.fastKeys >= maxFastKeys {
+			m.flag("inhumanly fast input")
+		}
+	} else {
+		m.fastKeys = 0
+	}
+	return false
+}
+
+// matchesTarget reports whether runes are exactly the next characters of the
+// target. Bursts that don't can't inflate a result.
+func (m *model) matchesTarget(runes []rune) bool {
+	for i, r := range runes {
+		if !isCharacterCorrect(m.currentInput+string(runes[:i]), m.targetText, string(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// burstWPM is the speed of typing n runes since the previous keystroke. A
+// burst with no previous keystroke in the round counts as instant.
+func burstWPM(n int, since time.Duration) float64 {
+	if since <= 0 || since > time.Hour {
+		return math.Inf(1)
+	}
+	return float64(n) / 5 / since.Minutes()
+}
+
+func (m *model) flag(reason string) {
+	if m.invalidReason == "" {
+		m.invalidReason = reason
+	}
+	m.status = "⚠ Result invalid (" + m.invalidReason + ") — it will not be recorded"
+}
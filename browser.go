@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var bookmarkKey = key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "bookmark"))
+
+// snippetItem is a snippet shown in the browser list
+type snippetItem struct {
+	snippet    snippet
+	bookmarked bool
+}
+
+func (i snippetItem) Title() string {
+	if i.bookmarked {
+		return "★ " + i.snippet.DisplayName()
+	}
+	return i.snippet.DisplayName()
+}
+
+func (i snippetItem) Description() string {
+	return fmt.Sprintf("%s • %s • %d lines", i.snippet.Package, filepath.Base(i.snippet.File), countLines(i.snippet.Text))
+}
+
+func (i snippetItem) FilterValue() string {
+	return i.snippet.Package + " " + i.snippet.DisplayName()
+}
+
+// bookmarkID identifies a snippet across runs by its file and qualified name
+func bookmarkID(s snippet) string {
+	return s.File + "#" + s.DisplayName()
+}
+
+// indexSnippets extracts every function of the wanted kinds in the
+// configured corpora
+func indexSnippets(cfg config) ([]snippet, error) {
+	var snippets []snippet
+	for _, corpusCfg := range corpusConfigs(cfg) {
+		goFiles, err := corpusFiles(corpusCfg)
+		if len(cfg.Corpora) > 0 && (err != nil || isFallback(corpusCfg, goFiles[0])) {
+			continue // Skip broken corpora like loadRandomFunction does
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range goFiles {
+			functions, err := extractFunctions(file)
+			if err != nil {
+				continue // Skip files that don't parse
+			}
+			for _, fn := range functions {
+				if wantKind(corpusCfg.Kinds, fn.Kind()) {
+					snippets = append(snippets, fn)
+				}
+			}
+		}
+	}
+	return snippets, nil
+}
+
+// snippetsIndexedMsg carries the browser's snippets once they're indexed
+type snippetsIndexedMsg struct {
+	gen      int
+	snippets []snippet
+	err      error
+}
+
+// openBrowser shows the browser straight away and indexes the corpus in the
+// background, since parsing a big folder takes a while
+func (m *model) openBrowser() tea.Cmd {
+	m.browser = list.New(nil, list.NewDefaultDelegate(), m.width, m.height-2)
+	m.browser.Title = "📚 Indexing snippets…"
+	m.browser.SetStatusBarItemName("snippet", "snippets")
+	m.browser.DisableQuitKeybindings()
+	m.browser.AdditionalShortHelpKeys = func() []key.Binding { return []key.Binding{bookmarkKey} }
+	m.browser.AdditionalFullHelpKeys = func() []key.Binding { return []key.Binding{bookmarkKey} }
+	m.showingBrowser = true
+
+	m.browserGen++
+	gen, cfg := m.browserGen, m.config
+	index := func() tea.Msg {
+		snippets, err := indexSnippets(cfg)
+		return snippetsIndexedMsg{gen: gen, snippets: snippets, err: err}
+	}
+	return tea.Batch(m.browser.StartSpinner(), index)
+}
+
+// showSnippets fills the browser with indexed snippets, bookmarked ones first
+func (m *model) showSnippets(msg snippetsIndexedMsg) tea.Cmd {
+	if msg.gen != m.browserGen {
+		return nil // Indexed for a browser that's since been closed
+	}
+	m.browser.StopSpinner()
+	if msg.err != nil {
+		m.err = msg.err
+		m.showingBrowser = false
+		return nil
+	}
+	snippets := msg.snippets
+
+	items := make([]snippetItem, len(snippets))
+	for i, s := range snippets {
+		items[i] = snippetItem{snippet: s, bookmarked: slices.Contains(m.config.Bookmarks, bookmarkID(s))}
+	}
+	sort.SliceStable(items, func(a, b int) bool {
+		return items[a].bookmarked && !items[b].bookmarked
+	})
+
+	listItems := make([]list.Item, len(items))
+	for i, item := range items {
+		listItems[i] = item
+	}
+
+	m.browser.Title = "📚 Snippets"
+	return m.browser.SetItems(listItems)
+}
+
+// updateBrowser handles keys while the snippet browser is open
+func (m model) updateBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// While typing a filter every key belongs to the list
+	if m.browser.SettingFilter() {
+		var cmd tea.Cmd
+		m.browser, cmd = m.browser.Update(msg)
+		return m, cmd
+	}
+
+	switch {
+	case msg.Type == tea.KeyEsc && m.browser.FilterState() == list.Unfiltered:
+		m.showingBrowser = false
+		m.browserGen++
+		return m, nil
+
+	case msg.Type == tea.KeyEnter:
+		item, ok := m.browser.SelectedItem().(snippetItem)
+		if !ok {
+			return m, nil
+		}
+		m.showingBrowser = false
+		m.applyPendingRules()
+		m.err = nil
+		m.challenge = ""
+		m.setSnippet(item.snippet.Text, item.snippet.File)
+		m.resetRound()
+		cmd := m.startStudy()
+		return m, cmd
+
+	case key.Matches(msg, bookmarkKey):
+		item, ok := m.browser.SelectedItem().(snippetItem)
+		if !ok {
+			return m, nil
+		}
+		id := bookmarkID(item.snippet)
+		item.bookmarked = !item.bookmarked
+		if item.bookmarked {
+			m.config.Bookmarks = append(m.config.Bookmarks, id)
+		} else {
+			m.config.Bookmarks = slices.DeleteFunc(m.config.Bookmarks, func(b string) bool { return b == id })
+		}
+		if err := saveConfig(m.savedConfig()); err != nil {
+			cmd := m.browser.NewStatusMessage(fmt.Sprintf("Could not save bookmarks: %v", err))
+			return m, cmd
+		}
+		cmd := m.browser.SetItem(m.browser.GlobalIndex(), item)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.browser, cmd = m.browser.Update(msg)
+	return m, cmd
+}
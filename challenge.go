@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// challengePrefix starts every challenge code and carries the format version
+const challengePrefix = "tv1-"
+
+// challenge identifies a snippet and the settings it should be played with,
+// independently of where the code is checked out
+type challenge struct {
+	Module    string // Module path from go.mod, "std" for the standard library
+	Path      string // File path relative to the module root
+	Func      string // Receiver-qualified function name
+	Hash      string // Prefix of the snippet's SHA-256
+	Mode      string
+	Blind     bool
+	Memory    bool
+	TimeLimit int
+	Lookahead int
+}
+
+// snippetHash is a short content hash used to detect changed snippets
+func snippetHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:6])
+}
+
+// newChallenge describes a snippet with the current settings
+func newChallenge(s snippet, cfg config) (challenge, error) {
+	c := challenge{
+		Func:      s.DisplayName(),
+		Hash:      snippetHash(s.Text),
+		Mode:      cfg.Mode,
+		Blind:     cfg.BlindMode,
+		Memory:    cfg.MemoryMode,
+		TimeLimit: cfg.MaxTimeLimit,
+		Lookahead: cfg.LookaheadLines,
+	}
+
+	if strings.HasPrefix(s.File, embeddedPrefix) {
+		c.Path = s.File
+		return c, nil
+	}
+
+	absPath, err := filepath.Abs(s.File)
+	if err != nil {
+		return challenge{}, err
+	}
+	root, module, err := findModuleRoot(filepath.Dir(absPath))
+	if err != nil {
+		return challenge{}, err
+	}
+	rel, err := filepath.Rel(root, absPath)
+	if err != nil {
+		return challenge{}, err
+	}
+	c.Module = module
+	c.Path = filepath.ToSlash(rel)
+	return c, nil
+}
+
+// encode packs the challenge into a short, copy-pasteable code
+func (c challenge) encode() string {
+	fields := []any{c.Module, c.Path, c.Func, c.Hash, c.Mode, c.Blind, c.Memory, c.TimeLimit, c.Lookahead}
+	data, _ := json.Marshal(fields) // Only strings, bools and ints, so this can't fail
+	return challengePrefix + base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeChallenge(code string) (challenge, error) {
+	payload, ok := strings.CutPrefix(strings.TrimSpace(code), challengePrefix)
+	if !ok {
+		return challenge{}, fmt.Errorf("not a challenge code")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return challenge{}, fmt.Errorf("malformed challenge code: %w", err)
+	}
+
+	var c challenge
+	fields := []any{&c.Module, &c.Path, &c.Func, &c.Hash, &c.Mode, &c.Blind, &c.Memory, &c.TimeLimit, &c.Lookahead}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return challenge{}, fmt.Errorf("malformed challenge code: %w", err)
+	}
+	if c.Func == "" {
+		return challenge{}, fmt.Errorf("malformed challenge code: no function name")
+	}
+	// Codes only point into a module or the built-in corpus, never anywhere else on disk
+	if c.Module == "" && !strings.HasPrefix(c.Path, embeddedPrefix) || c.Module != "" && !filepath.IsLocal(filepath.FromSlash(c.Path)) {
+		return challenge{}, fmt.Errorf("malformed challenge code: bad path %q", c.Path)
+	}
+	c.Mode = normalizeMode(c.Mode)
+	return c, nil
+}
+
+// resolve finds the challenge's file in a local checkout under one of the
+// folders, the standard library or the module cache
+func (c challenge) resolve(folders []string) (string, error) {
+	if c.Module == "" {
+		if !strings.HasPrefix(c.Path, embeddedPrefix) {
+			return "", fmt.Errorf("challenge path %q is outside the built-in corpus", c.Path)
+		}
+		return c.Path, nil
+	}
+	rel := filepath.FromSlash(c.Path)
+
+	for _, folder := range folders {
+		if root, module, err := findModuleRoot(folder); err == nil && module == c.Module {
+			return filepath.Join(root, rel), nil
+		}
+	}
+
+	env, err := goEnv()
+	if err != nil {
+		return "", err
+	}
+	if c.Module == "std" {
+		return filepath.Join(env["GOROOT"], "src", rel), nil
+	}
+	if dir, ok := latestCachedModule(env["GOMODCACHE"], c.Module); ok {
+		return filepath.Join(dir, rel), nil
+	}
+	return "", fmt.Errorf("module %s is neither checked out under %s nor in the module cache", c.Module, strings.Join(folders, ", "))
+}
+
+// load finds the challenge's snippet. A non-empty warning means the snippet
+// was found but its content no longer matches the challenge.
+func (c challenge) load(folders []string) (snippet, string, error) {
+	path, err := c.resolve(folders)
+	if err != nil {
+		return snippet{}, "", err
+	}
+	s, err := findFunction(path, c.Func, nil)
+	if err != nil {
+		return snippet{}, "", err
+	}
+	if snippetHash(s.Text) != c.Hash {
+		return s, fmt.Sprintf("⚠ %s has changed since this challenge was created", c.Func), nil
+	}
+	return s, "", nil
+}
+
+// apply plays the challenge with the settings it was created with
+func (c challenge) apply(cfg config) config {
+	cfg.Mode = c.Mode
+	cfg.BlindMode = c.Blind
+	cfg.MemoryMode = c.Memory
+	cfg.MaxTimeLimit = c.TimeLimit
+	cfg.LookaheadLines = c.Lookahead
+	return cfg
+}
+
+// withChallengeSettings returns cfg with the settings a challenge controls
+// taken from src
+func withChallengeSettings(cfg, src config) config {
+	cfg.Mode = src.Mode
+	cfg.BlindMode = src.BlindMode
+	cfg.MemoryMode = src.MemoryMode
+	cfg.MaxTimeLimit = src.MaxTimeLimit
+	cfg.LookaheadLines = src.LookaheadLines
+	return cfg
+}
+
+// savedConfig is the config to save: rules reloaded during the round that
+// haven't applied yet are saved as they are on disk, and challenge settings
+// for the session are left out
+func (m model) savedConfig() config {
+	cfg := m.config
+	if m.pendingRules != nil {
+		cfg = withRoundRules(cfg, *m.pendingRules)
+	}
+	if m.ownSettings != nil {
+		cfg = withChallengeSettings(cfg, *m.ownSettings)
+	}
+	return cfg
+}
+
+// findModuleRoot walks up from dir to the nearest go.mod and returns its
+// directory and module path
+func findModuleRoot(dir string) (string, string, error) {
+	dir, err := expandHome(dir)
+	if err != nil {
+		return "", "", err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		if module, err := readModulePath(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, module, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("no go.mod found above %s", dir)
+		}
+		dir = parent
+	}
+}
+
+// readModulePath returns the path from a go.mod's module directive
+func readModulePath(goModPath string) (string, error) {
+	f, err := os.Open(goModPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no module directive", goModPath)
+}
+
+// shareChallenge shows the code for the current snippet in the status line
+func (m *model) shareChallenge() {
+	s, err := findSnippet(m.currentFile, m.targetText)
+	if err != nil {
+		m.status = fmt.Sprintf("Can't share this snippet: %v", err)
+		return
+	}
+	c, err := newChallenge(s, m.config)
+	if err != nil {
+		m.status = fmt.Sprintf("Can't share this snippet: %v", err)
+		return
+	}
+	m.status = "Challenge code: " + c.encode()
+}
+
+// findSnippet returns the function in file whose text is text
+func findSnippet(file, text string) (snippet, error) {
+	functions, err := extractFunctions(file)
+	if err != nil {
+		return snippet{}, err
+	}
+	for _, fn := range functions {
+		if fn.Text == text {
+			return fn, nil
+		}
+	}
+	return snippet{}, fmt.Errorf("only functions can be shared")
+}
+
+// playChallenge loads the challenge's snippet and plays it with the
+// challenge's settings for the rest of the session
+func (m *model) playChallenge(code string) (tea.Cmd, error) {
+	c, err := decodeChallenge(code)
+	if err != nil {
+		return nil, err
+	}
+	var folders []string
+	for _, corpusCfg := range corpusConfigs(m.config) {
+		if corpusCfg.FolderPath != "" {
+			folders = append(folders, corpusCfg.FolderPath)
+		}
+	}
+	s, warning, err := c.load(folders)
+	if err != nil {
+		return nil, err
+	}
+
+	m.applyPendingRules()
+	if m.ownSettings == nil {
+		own := m.config
+		m.ownSettings = &own
+	}
+	m.config = c.apply(m.config)
+	m.err = nil
+	m.setSnippet(s.Text, s.File)
+	m.resetRound()
+	m.challenge = c.encode()
+	m.status = warning
+	return m.startStudy(), nil
+}
+
+func (m *model) openChallengePrompt() {
+	m.challengeInput = textinput.New()
+	m.challengeInput.Placeholder = challengePrefix + "..."
+	m.challengeInput.CharLimit = 1000
+	m.challengeInput.Width = 60
+	m.challengeInput.Focus()
+	m.showingChallengePrompt = true
+}
+
+// updateChallengePrompt handles keys while a challenge code is being entered.
+// Pasting is expected here, so keys skip the anti-cheat screening.
+func (m model) updateChallengePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.showingChallengePrompt = false
+		return m, nil
+
+	case tea.KeyEnter:
+		cmd, err := m.playChallenge(m.challengeInput.Value())
+		if err != nil {
+			m.challengeInput.Err = err
+			return m, nil
+		}
+		m.showingChallengePrompt = false
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.challengeInput, cmd = m.challengeInput.Update(msg)
+	m.challengeInput.Err = nil
+	return m, cmd
+}
+
+func (m model) renderChallengePrompt() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("⚡ Play a Challenge"))
+	b.WriteString("\n\n")
+	b.WriteString(formLabelStyle.Render("Challenge Code:"))
+	b.WriteString("\n")
+	b.WriteString(m.challengeInput.View())
+	b.WriteString("\n\n")
+	if m.challengeInput.Err != nil {
+		b.WriteString(errorStyle.Render(m.challengeInput.Err.Error()))
+		b.WriteString("\n\n")
+	}
+	b.WriteString(helpStyle.Render("Paste a code from a teammate • Enter to play • Esc to cancel"))
+	return b.String()
+}
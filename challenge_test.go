@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestChallengeRoundTrip(t *testing.T) {
+	tests := []challenge{
+		{Module: "github.com/spf13/viper", Path: "viper.go", Func: "(*Viper).Get", Hash: "0123456789ab", Mode: modeNormal},
+		{Module: "std", Path: "net/http/server.go", Func: "ListenAndServe", Hash: "ba9876543210", Mode: modeSuddenDeath, Blind: true, TimeLimit: 60, Lookahead: 2},
+		{Path: embeddedPrefix + "snippets/errors.go", Func: "wrap", Mode: modePerfectionist, Memory: true, Lookahead: -1},
+		{Module: "example.com/ünïcode", Path: "dir/файл.go", Func: "Grüße", Mode: modeNormal},
+	}
+	for _, want := range tests {
+		code := want.encode()
+		got, err := decodeChallenge(code)
+		if err != nil {
+			t.Errorf("decodeChallenge(%q): %v", code, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("round trip of %+v gave %+v", want, got)
+		}
+	}
+}
+
+func TestDecodeChallengeNormalizesMode(t *testing.T) {
+	code := challenge{Module: "std", Path: "fmt/print.go", Func: "Println", Mode: "Sudden_Death"}.encode()
+	c, err := decodeChallenge("  " + code + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Mode != modeSuddenDeath {
+		t.Errorf("mode = %q, want %q", c.Mode, modeSuddenDeath)
+	}
+}
+
+func TestDecodeChallengeMalformed(t *testing.T) {
+	encode := func(json string) string {
+		return challengePrefix + base64.RawURLEncoding.EncodeToString([]byte(json))
+	}
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"empty", ""},
+		{"no prefix", "abc"},
+		{"other version", "tv2-" + base64.RawURLEncoding.EncodeToString([]byte(`["std","fmt/print.go","Println"]`))},
+		{"bad base64", challengePrefix + "!!!"},
+		{"not json", encode("hello")},
+		{"object", encode(`{"Func":"Println"}`)},
+		{"wrong types", encode(`[1,2,3]`)},
+		{"no function", encode(`["std","fmt/print.go",""]`)},
+		{"missing function", encode(`["std","fmt/print.go"]`)},
+		{"absolute path without module", encode(`["","/etc/passwd","Println"]`)},
+		{"relative path without module", encode(`["","fmt/print.go","Println"]`)},
+		{"absolute path in module", encode(`["std","/etc/passwd","Println"]`)},
+		{"path escaping module", encode(`["std","../../etc/passwd","Println"]`)},
+		{"empty path in module", encode(`["std","","Println"]`)},
+	}
+	for _, tt := range tests {
+		if c, err := decodeChallenge(tt.code); err == nil {
+			t.Errorf("%s: decodeChallenge(%q) = %+v, want an error", tt.name, tt.code, c)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func newRootCmd() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "typing-vibes",
+		Short:         "A typing speed test using real Go functions",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if name := strings.TrimSpace(viper.GetString("profile")); name != "" {
+				return checkProfileName(name)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProgram(initialModel())
+		},
+	}
+
+	// Flags are bound to the config keys so precedence is flag > env > file > default
+	flags := root.PersistentFlags()
+	flags.String("dir", "", "folder to pick functions from")
+	flags.String("source", "", "snippet source: folder, git-recent, packages or embedded")
+	flags.StringSlice("packages", nil, "import paths for the packages source")
+	flags.Int("min", 0, "minimum function length in lines")
+	flags.Int("max", 0, "maximum function length in lines")
+	flags.Int("time", 0, "time limit in seconds, 0 = no limit")
+	flags.String("mode", "", "challenge mode: normal, sudden-death or perfectionist")
+	flags.Int64("seed", 0, "seed for reproducible snippet selection, 0 = random")
+	flags.Bool("daily", false, "play today's daily challenge, shared by everyone on the same corpus revision")
+	flags.String("preset", "", "named preset to apply, see the presets command")
+	flags.String("profile", "", "user profile with its own settings and history, see the profiles command")
+	flags.String("config", "", "config file to use instead of the one in the config directory")
+	flags.Bool("cwd-config", false, "use ./typing_vibes.yaml from the current directory if there is one")
+	bindFlag(flags.Lookup("dir"), "folder_path")
+	bindFlag(flags.Lookup("source"), "source")
+	bindFlag(flags.Lookup("packages"), "packages")
+	bindFlag(flags.Lookup("min"), "min_lines")
+	bindFlag(flags.Lookup("max"), "max_lines")
+	bindFlag(flags.Lookup("time"), "max_time_limit")
+	bindFlag(flags.Lookup("mode"), "mode")
+	bindFlag(flags.Lookup("seed"), "seed")
+	bindFlag(flags.Lookup("daily"), "daily")
+	bindFlag(flags.Lookup("preset"), "preset")
+	bindFlag(flags.Lookup("profile"), "profile")
+	bindFlag(flags.Lookup("config"), "config")
+	bindFlag(flags.Lookup("cwd-config"), "cwd_config")
+
+	viper.SetEnvPrefix("TYPING_VIBES")
+	viper.AutomaticEnv()
+
+	root.AddCommand(newRunCmd(), newFileCmd(), newChallengeCmd(), newPresetsCmd(), newThemesCmd(), newProfilesCmd(), newStatsCmd(), newConfigCmd())
+	return root
+}
+
+// boundFlags are the flags bound to config keys, by key
+var boundFlags = make(map[string]*pflag.Flag)
+
+func bindFlag(flag *pflag.Flag, key string) {
+	boundFlags[key] = flag
+	if err := viper.BindPFlag(key, flag); err != nil {
+		panic(err) // Only fails for a nil flag, which is a programming error
+	}
+}
+
+// runProgram starts the TUI with a prepared model
+func runProgram(m model) error {
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
+
+func newRunCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "run",
+		Short: "Start a practice session (the default)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProgram(initialModel())
+		},
+	}
+}
+
+func newFileCmd() *cobra.Command {
+	var funcName string
+	cmd := &cobra.Command{
+		Use:   "file <path>",
+		Short: "Practise a specific function from a file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := initialModel()
+			s, err := findFunction(args[0], funcName, m.rng)
+			if err != nil {
+				return err
+			}
+			m.setSnippet(s.Text, s.File)
+			m.startStudy()
+			return runProgram(m)
+		},
+	}
+	cmd.Flags().StringVar(&funcName, "func", "", "function name, e.g. Name or (*Type).Name (default: random)")
+	return cmd
+}
+
+func newChallengeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "challenge",
+		Short: "Share a function as a challenge code, or play one",
+	}
+
+	var funcName string
+	create := &cobra.Command{
+		Use:   "create <path>",
+		Short: "Print a challenge code for a function with the current mode settings",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if funcName == "" {
+				return fmt.Errorf("--func must name a function")
+			}
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			s, err := findFunction(args[0], funcName, nil)
+			if err != nil {
+				return err
+			}
+			c, err := newChallenge(s, cfg)
+			if err != nil {
+				return err
+			}
+			cmd.Println(c.encode())
+			return nil
+		},
+	}
+	create.Flags().StringVar(&funcName, "func", "", "function name, e.g. Name or (*Type).Name")
+	create.MarkFlagRequired("func")
+
+	play := &cobra.Command{
+		Use:   "play <code>",
+		Short: "Practise the function a challenge code points to",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := initialModel()
+			if _, err := m.playChallenge(args[0]); err != nil {
+				return err
+			}
+			return runProgram(m)
+		},
+	}
+
+	cmd.AddCommand(create, play)
+	return cmd
+}
+
+func newPresetsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "presets",
+		Short: "List the presets and the settings they override",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			presets := loadPresets()
+			for _, name := range presetNames() {
+				marker := "  "
+				if name == cfg.Preset {
+					marker = "* "
+				}
+				var overrides []string
+				for _, key := range slices.Sorted(maps.Keys(presets[name])) {
+					overrides = append(overrides, fmt.Sprintf("%s=%v", key, presets[name][key]))
+				}
+				cmd.Printf("%s%-14s %s\n", marker, name, strings.Join(overrides, " "))
+			}
+			return nil
+		},
+	}
+}
+
+func newThemesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "themes",
+		Short: "List the colour themes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			for _, name := range themeNames() {
+				marker := "  "
+				if name == cfg.Theme {
+					marker = "* "
+				}
+				source := "built in"
+				if _, err := os.Stat(themePath(name)); err == nil {
+					source = contractHome(themePath(name))
+				}
+				cmd.Printf("%s%-14s %s\n", marker, name, source)
+			}
+			if cfg.ThemeErr != nil {
+				cmd.PrintErrf("warning: %v, using the %s theme\n", cfg.ThemeErr, defaultTheme)
+			}
+			return nil
+		},
+	}
+}
+
+func newProfilesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profiles",
+		Short: "List user profiles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, name := range append([]string{""}, profileNames()...) {
+				marker := "  "
+				if name == profileName() {
+					marker = "* "
+				}
+				if name == "" {
+					name = "(default)"
+				}
+				cmd.Println(marker + name)
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "add <name>",
+		Short: "Create a profile, starting from the shared settings",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkProfileName(args[0]); err != nil {
+				return err
+			}
+			return os.MkdirAll(filepath.Join(configDir(), "profiles", args[0]), 0755)
+		},
+	})
+
+	return cmd
+}
+
+func newStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Summarise recorded results by category",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := loadConfig(); err != nil {
+				return err
+			}
+			results, err := loadHistory()
+			if err != nil {
+				return err
+			}
+			printStats(cmd, results)
+			return nil
+		},
+	}
+}
+
+// printStats writes one row per category with completion and speed figures
+func printStats(cmd *cobra.Command, results []result) {
+	if len(results) == 0 {
+		cmd.Println("No results recorded yet.")
+		return
+	}
+
+	type summary struct {
+		rounds, completed           int
+		bestWPM, totalWPM, totalAcc float64
+	}
+	summaries := make(map[string]*summary)
+	for _, r := range results {
+		label := r.categoryLabel()
+		if summaries[label] == nil {
+			summaries[label] = &summary{}
+		}
+		s := summaries[label]
+		s.rounds++
+		s.totalWPM += r.WPM
+		s.totalAcc += r.Accuracy
+		if r.Outcome == outcomeCompleted {
+			s.completed++
+			s.bestWPM = max(s.bestWPM, r.WPM)
+		}
+	}
+
+	labels := make([]string, 0, len(summaries))
+	for label := range summaries {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CATEGORY\tROUNDS\tCOMPLETED\tBEST WPM\tAVG WPM\tAVG ACCURACY")
+	for _, label := range labels {
+		s := summaries[label]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\t%.1f\t%.1f%%\n",
+			label, s.rounds, s.completed, s.bestWPM, s.totalWPM/float64(s.rounds), s.totalAcc/float64(s.rounds))
+	}
+	w.Flush()
+}
+
+// settingKeys lists the settings config get and set know about, leaving out
+// those that only come from flags and environment variables
+func settingKeys() []string {
+	keys := slices.DeleteFunc(viper.AllKeys(), func(key string) bool {
+		return slices.Contains(runOnlyKeys, key)
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read or change settings",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "get [key]",
+		Short: "Print one setting, or all of them",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := loadConfig(); err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				for _, key := range settingKeys() {
+					cmd.Printf("%s: %v\n", key, viper.Get(key))
+				}
+				return nil
+			}
+			if !slices.Contains(settingKeys(), args[0]) {
+				return fmt.Errorf("unknown setting %q", args[0])
+			}
+			cmd.Println(viper.Get(args[0]))
+			return nil
+		},
+	})
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting and save it to the config file",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig()
+			if err != nil {
+				return err
+			}
+			key, value := args[0], args[1]
+			switch {
+			case slices.Contains(runOnlyKeys, key):
+				return fmt.Errorf("%s only applies to a single run, use a flag or TYPING_VIBES_%s", key, strings.ToUpper(key))
+			case key == "version":
+				return fmt.Errorf("version is the config file format, it's updated automatically")
+			case key == "corpora":
+				return fmt.Errorf("corpora are lists of settings, edit them in %s", configPath())
+			case !slices.Contains(settingKeys(), key):
+				return fmt.Errorf("unknown setting %q", key)
+			case !slices.Contains(savedKeys(), key):
+				return fmt.Errorf("%s can't be set from the command line, edit it in %s", key, configPath())
+			}
+			if cfg.repoControls(key) {
+				return fmt.Errorf("%s is set by %s, edit it there", key, cfg.RepoConfig)
+			}
+
+			// Convert the value to the type of the setting's current value
+			var typed any = value
+			switch viper.Get(key).(type) {
+			case int:
+				n, err := strconv.Atoi(value)
+				if err != nil {
+					return fmt.Errorf("%s must be a number", key)
+				}
+				typed = n
+			case bool:
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return fmt.Errorf("%s must be true or false", key)
+				}
+				typed = b
+			case []string, []any:
+				typed = splitList(value)
+			}
+
+			// Check the value like the settings form does, without failing
+			// on settings that were already invalid
+			before := validateSettings(cfg)
+			viper.Set(key, typed)
+			if cfg, err = loadConfig(); err != nil {
+				return err
+			}
+			for field, msg := range validateSettings(cfg) {
+				if before[field] != msg {
+					return fmt.Errorf("%s %s", field, msg)
+				}
+			}
+			return saveConfig(cfg)
+		},
+	})
+
+	return cmd
+}
@@ -1,52 +1,404 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/spf13/viper"
 )
 
 type config struct {
+	Source       string // folder, git-recent, packages or embedded
 	FolderPath   string
+	Packages     []string // import paths used by the packages source
 	MinLines     int
 	MaxLines     int
-	MaxTimeLimit int // seconds, 0 = no limit
-}
+	MaxTimeLimit int    // seconds, 0 = no limit
+	Mode         string // normal, sudden-death or perfectionist
+	// Forbid backspacing into tokens that were already typed correctly
+	LockCompletedTokens bool
+	BlindMode           bool // Hide correctness feedback until the round ends
+	MemoryMode          bool // Study the snippet first, then type it from recall
+	StudySeconds        int  // seconds, 0 = study until a key is pressed
+	LookaheadLines      int  // target lines shown after the cursor line, -1 = all
+	Theme               string
 
-func loadConfig() config {
-	viper.SetConfigName("typing_vibes")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath("$HOME/.config/typing_vibes")
-	viper.AddConfigPath(".")
+	// git-recent source window: the last N commits if set, otherwise N days,
+	// optionally narrowed to the current branch's diff against a base branch
+	GitRecentDays    int
+	GitRecentCommits int
+	GitBaseBranch    string
+
+	// Only use functions mostly written by this author according to git blame
+	AuthorEmail string
+
+	Ignore  []string // Glob patterns for files and directories to skip
+	Kinds   []string // Snippet kinds to practise, empty = all
+	Corpora []corpus // Weighted sources mixed together, replacing the settings above
+
+	Bookmarks []string // Favourite snippets as "file#(*Type).Func"
+
+	Preset string // Active preset whose overrides are applied, empty = none
 
+	// Repository config file layered over the user's settings, and the keys it sets
+	RepoConfig string
+	RepoKeys   []string
+
+	ThemeErr error // Why Theme can't be used, when the default theme is shown instead
+}
+
+// loadConfig reads the settings. A config file that can't be read is
+// reported, with the settings read so far still returned.
+func loadConfig() (config, error) {
 	// Set defaults
 	homeDir, _ := os.UserHomeDir()
+	viper.SetDefault("source", sourceFolder)
 	viper.SetDefault("folder_path", filepath.Join(homeDir, "code"))
+	viper.SetDefault("packages", []string{})
 	viper.SetDefault("min_lines", 5)
 	viper.SetDefault("max_lines", 50)
 	viper.SetDefault("max_time_limit", 30)
+	viper.SetDefault("mode", modeNormal)
+	viper.SetDefault("lock_completed_tokens", false)
+	viper.SetDefault("blind_mode", false)
+	viper.SetDefault("memory_mode", false)
+	viper.SetDefault("study_seconds", 15)
+	viper.SetDefault("lookahead_lines", -1)
+	viper.SetDefault("theme", defaultTheme)
+	viper.SetDefault("git_recent_days", 14)
+	viper.SetDefault("git_recent_commits", 0)
+	viper.SetDefault("git_base_branch", "")
+	viper.SetDefault("author_email", "")
+	viper.SetDefault("ignore", []string{})
+	viper.SetDefault("kinds", []string{})
+	viper.SetDefault("corpora", []corpus{})
+	viper.SetDefault("bookmarks", []string{})
+
+	// Forget anything read by an earlier load
+	viper.SetConfigType("yaml")
+	viper.ReadConfig(strings.NewReader(""))
+
+	// The active profile's settings override the shared ones
+	files := []string{sharedConfigPath()}
+	if profileName() != "" {
+		files = append(files, configPath())
+	}
+	var errs []error
+	for _, path := range files {
+		settings, _, err := readConfigFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // No config yet
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+		dropRunOnlyKeys(settings)
+		viper.MergeConfigMap(settings)
+	}
+
+	// A repository's own config curates practice on it
+	repoConfig := findRepoConfig(viper.GetString("folder_path"))
+	var repoKeys []string
+	if repoConfig != "" {
+		var err error
+		if repoKeys, err = mergeRepoConfig(repoConfig); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", repoConfig, err))
+		}
+	}
+
+	// Layer the active preset over the files, leaving flags and environment
+	// variables on top
+	name := presetName(viper.GetString("preset"))
+	if overrides, ok := loadPresets()[name]; ok {
+		viper.MergeConfigMap(maps.Clone(overrides))
+	} else {
+		name = ""
+	}
+	cfg := configFrom(viper.GetViper())
+	cfg.Preset = name
+	cfg.RepoConfig = repoConfig
+	cfg.RepoKeys = repoKeys
+
+	if len(errs) > 0 {
+		// Keep the current theme, as the current settings are kept too
+		return cfg, errors.Join(errs...)
+	}
+	// Styles follow the theme wherever the settings come from. A theme that
+	// can't be used only falls back to the default, so the setting can still
+	// be fixed with the app itself.
+	cfg.ThemeErr = useTheme(cfg.Theme)
+	return cfg, nil
+}
 
-	viper.ReadInConfig() // Ignore error if config doesn't exist
+// configFrom reads the settings from a viper instance
+func configFrom(v *viper.Viper) config {
+	var corpora []corpus
+	v.UnmarshalKey("corpora", &corpora) // Malformed entries are left empty
 
 	return config{
-		FolderPath:   viper.GetString("folder_path"),
-		MinLines:     viper.GetInt("min_lines"),
-		MaxLines:     viper.GetInt("max_lines"),
-		MaxTimeLimit: viper.GetInt("max_time_limit"),
+		Source:       normalizeSource(v.GetString("source")),
+		FolderPath:   v.GetString("folder_path"),
+		Packages:     v.GetStringSlice("packages"),
+		MinLines:     v.GetInt("min_lines"),
+		MaxLines:     v.GetInt("max_lines"),
+		MaxTimeLimit: v.GetInt("max_time_limit"),
+		Mode:         normalizeMode(v.GetString("mode")),
+
+		LockCompletedTokens: v.GetBool("lock_completed_tokens"),
+		BlindMode:           v.GetBool("blind_mode"),
+		MemoryMode:          v.GetBool("memory_mode"),
+		StudySeconds:        v.GetInt("study_seconds"),
+		LookaheadLines:      v.GetInt("lookahead_lines"),
+		Theme:               v.GetString("theme"),
+
+		GitRecentDays:    v.GetInt("git_recent_days"),
+		GitRecentCommits: v.GetInt("git_recent_commits"),
+		GitBaseBranch:    v.GetString("git_base_branch"),
+
+		AuthorEmail: v.GetString("author_email"),
+
+		Ignore:  v.GetStringSlice("ignore"),
+		Kinds:   v.GetStringSlice("kinds"),
+		Corpora: corpora,
+
+		Bookmarks: v.GetStringSlice("bookmarks"),
 	}
 }
 
+// saveConfig writes the settings to the active profile's config file,
+// keeping anything else already in it
 func saveConfig(cfg config) error {
-	viper.Set("folder_path", cfg.FolderPath)
-	viper.Set("min_lines", cfg.MinLines)
-	viper.Set("max_lines", cfg.MaxLines)
-	viper.Set("max_time_limit", cfg.MaxTimeLimit)
+	path := configPath()
+	settings, version, err := readConfigFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		// Don't replace a file we couldn't read, the user may be halfway through editing it
+		return fmt.Errorf("not saving settings, %s: %w", path, err)
+	}
+	out := viper.New()
+	out.MergeConfigMap(settings) // Keeps settings we don't know about
+
+	values := map[string]any{
+		"ignore":  cfg.Ignore,
+		"kinds":   cfg.Kinds,
+		"corpora": cfg.Corpora,
+	}
+	for _, s := range settingsSchema {
+		values[s.key] = s.value(&cfg)
+	}
+
+	// Settings controlled by the active preset are saved into the preset
+	overrides := maps.Clone(loadPresets()[cfg.Preset])
+	for key, value := range values {
+		if cfg.repoControls(key) {
+			continue // Belongs to the repository config
+		}
+		if fromRunOverride(key, value) {
+			continue // A flag or environment variable for this run only
+		}
+		if _, ok := overrides[key]; ok {
+			overrides[key] = value
+			continue
+		}
+		out.Set(key, value)
+	}
+	if cfg.Preset != "" && !cfg.repoControls("presets."+cfg.Preset) && presetChanged(cfg.Preset, overrides) {
+		out.Set("presets."+cfg.Preset, overrides)
+	}
+	if !cfg.repoControls("preset") && !fromRunOverride("preset", cfg.Preset) {
+		out.Set("preset", cfg.Preset)
+	}
+	out.Set("bookmarks", cfg.Bookmarks)
+	out.Set("version", configVersion)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := backupConfig(path, version); err != nil {
+		return err
+	}
+	return out.WriteConfigAs(path)
+}
+
+// savedKeys are the settings saveConfig writes
+func savedKeys() []string {
+	keys := []string{"ignore", "kinds", "corpora", "preset", "bookmarks"}
+	for _, s := range settingsSchema {
+		keys = append(keys, s.key)
+	}
+	return keys
+}
 
-	configDir := filepath.Join(os.Getenv("HOME"), ".config", "typing_vibes")
-	os.MkdirAll(configDir, 0755)
+// fromRunOverride reports whether value is what a flag or TYPING_VIBES_
+// environment variable set a key to, so it shouldn't be saved
+func fromRunOverride(key string, value any) bool {
+	raw, ok := os.LookupEnv("TYPING_VIBES_" + strings.ToUpper(key))
+	if flag, bound := boundFlags[key]; bound && flag.Changed {
+		raw, ok = flag.Value.String(), true
+	}
+	if !ok {
+		return false
+	}
+	if key == "preset" {
+		raw = presetName(raw)
+	}
+	if list, isList := value.([]string); isList {
+		// Flags print lists as [a,b], environment variables separate them with spaces
+		return slices.Equal(list, strings.Fields(strings.NewReplacer("[", "", "]", "", ",", " ").Replace(raw)))
+	}
+	return fmt.Sprint(value) == raw
+}
+
+// runOnlyKeys choose which config files are read and written, or how this
+// run picks snippets, so they're only taken from flags and environment
+// variables, never from a config file
+var runOnlyKeys = []string{"config", "cwd_config", "profile", "seed", "daily"}
 
-	configPath := filepath.Join(configDir, "typing_vibes.yaml")
-	return viper.WriteConfigAs(configPath)
+// dropRunOnlyKeys removes the keys a config file or preset can't set. The
+// version is the file's own and not a setting either.
+func dropRunOnlyKeys(settings map[string]any) {
+	for _, key := range runOnlyKeys {
+		delete(settings, key)
+	}
+	delete(settings, "version")
 }
 
+// configVersion is the version of the config file format written by saveConfig.
+// Files without a version are from before versioning and count as 0.
+const configVersion = 1
+
+// configMigrations upgrade a config file's settings in place, where entry i
+// moves version i to version i+1. Add one whenever a setting is renamed,
+// moved or changes meaning, rather than breaking older files.
+var configMigrations = []func(settings map[string]any){
+	// Spell modes and sources the way they're written today, e.g.
+	// "sudden_death" was accepted but never written back
+	func(settings map[string]any) {
+		if mode, ok := settings["mode"].(string); ok {
+			settings["mode"] = normalizeMode(mode)
+		}
+		if source, ok := settings["source"].(string); ok {
+			settings["source"] = normalizeSource(source)
+		}
+	},
+}
+
+// readConfigFile reads a config file's settings, migrated to configVersion,
+// and the version the file was written with
+func readConfigFile(path string) (map[string]any, int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, 0, err
+	}
+	settings := v.AllSettings()
+	version := v.GetInt("version")
+
+	if version < 0 {
+		return settings, version, fmt.Errorf("invalid config version %d", version)
+	}
+	if version > configVersion {
+		// Settings may mean something else now, so use them but never save over them
+		return settings, version, fmt.Errorf("written by a newer version of typing_vibes (config version %d, this one understands %d)", version, configVersion)
+	}
+	for _, migrate := range configMigrations[version:] {
+		migrate(settings)
+	}
+	settings["version"] = configVersion
+	return settings, version, nil
+}
+
+// backupConfig copies a config file before it's rewritten. A file about to be
+// migrated gets a backup named after its version, which later saves don't
+// replace, e.g. typing_vibes.yaml.v0.bak.
+func backupConfig(path string, version int) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil // Nothing to back up
+	}
+	if err != nil {
+		return err
+	}
+	backup := path + ".bak"
+	if version < configVersion {
+		backup = fmt.Sprintf("%s.v%d.bak", path, version)
+	}
+	return os.WriteFile(backup, data, 0644)
+}
+
+// configPath is the config file of the active profile
+func configPath() string {
+	if profileName() != "" {
+		return filepath.Join(profileConfigDir(), "typing_vibes.yaml")
+	}
+	return sharedConfigPath()
+}
+
+// sharedConfigPath is the config file every profile starts from: the --config
+// file if given, ./typing_vibes.yaml if --cwd-config is set and it exists,
+// otherwise the one in the config directory
+func sharedConfigPath() string {
+	if path := viper.GetString("config"); path != "" {
+		if expanded, err := expandHome(path); err == nil {
+			return expanded
+		}
+		return path
+	}
+	if viper.GetBool("cwd_config") {
+		if path, err := filepath.Abs("typing_vibes.yaml"); err == nil {
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+	}
+	return filepath.Join(configDir(), "typing_vibes.yaml")
+}
+
+// configDir holds settings, $XDG_CONFIG_HOME/typing_vibes or ~/.config/typing_vibes
+func configDir() string {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// stateDir holds history, $XDG_STATE_HOME/typing_vibes or ~/.local/state/typing_vibes.
+// The spec puts history here, as it's worth keeping but not portable like data.
+func stateDir() string {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// legacyDataDir is where history was kept before it moved to stateDir
+func legacyDataDir() string {
+	return xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// xdgDir returns the app's directory under an XDG base directory, falling
+// back to a path under the home directory when the variable is unset.
+// Relative values are invalid according to the spec and ignored.
+func xdgDir(env, fallback string) string {
+	base := os.Getenv(env)
+	if !filepath.IsAbs(base) {
+		homeDir, _ := os.UserHomeDir()
+		base = filepath.Join(homeDir, fallback)
+	}
+	return filepath.Join(base, "typing_vibes")
+}
+
+// normalizeSource maps user input to a known snippet source, falling back to folder
+func normalizeSource(source string) string {
+	source = strings.ToLower(strings.TrimSpace(source))
+	for _, known := range sources {
+		if source == known {
+			return known
+		}
+	}
+	return sourceFolder
+}
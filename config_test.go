@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadConfigFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		yaml        string
+		wantVersion int
+		wantErr     bool
+		want        map[string]any // Settings to check, nil when none are returned
+	}{
+		{
+			name:        "unversioned file is migrated",
+			yaml:        "mode: Sudden_Death\nsource: GIT-RECENT\nmin_lines: 3\n",
+			wantVersion: 0,
+			want:        map[string]any{"mode": modeSuddenDeath, "source": sourceGitRecent, "min_lines": 3, "version": configVersion},
+		},
+		{
+			name:        "unknown spellings fall back to defaults",
+			yaml:        "mode: speedrun\nsource: nowhere\n",
+			wantVersion: 0,
+			want:        map[string]any{"mode": modeNormal, "source": sourceFolder},
+		},
+		{
+			name:        "current version is left alone",
+			yaml:        "version: 1\nmode: Sudden_Death\n",
+			wantVersion: 1,
+			want:        map[string]any{"mode": "Sudden_Death", "version": configVersion},
+		},
+		{
+			name:        "newer version is read but reported",
+			yaml:        "version: 2\nmode: sudden-death\n",
+			wantVersion: 2,
+			wantErr:     true,
+			want:        map[string]any{"mode": modeSuddenDeath, "version": 2},
+		},
+		{
+			name:        "negative version",
+			yaml:        "version: -1\nmode: normal\n",
+			wantVersion: -1,
+			wantErr:     true,
+			want:        map[string]any{"mode": modeNormal},
+		},
+		{
+			name:    "invalid yaml",
+			yaml:    "mode: [normal\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "typing_vibes.yaml")
+		if err := os.WriteFile(path, []byte(tt.yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		settings, version, err := readConfigFile(path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if version != tt.wantVersion {
+			t.Errorf("%s: version = %d, want %d", tt.name, version, tt.wantVersion)
+		}
+		if tt.want == nil && settings != nil {
+			t.Errorf("%s: settings = %v, want none", tt.name, settings)
+		}
+		for key, want := range tt.want {
+			if got := settings[key]; got != want {
+				t.Errorf("%s: %s = %#v, want %#v", tt.name, key, got, want)
+			}
+		}
+	}
+}
+
+func TestReadConfigFileMissing(t *testing.T) {
+	if _, _, err := readConfigFile(filepath.Join(t.TempDir(), "missing.yaml")); !os.IsNotExist(err) {
+		t.Errorf("err = %v, want a not exist error", err)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+// configSettle is how long a config file must stay untouched after a change
+// before it's reloaded, as editors often save in several steps
+const configSettle = 100 * time.Millisecond
+
+// configChangedMsg reports that a config file in use was changed on disk
+type configChangedMsg struct{}
+
+// configWatcher reports changes to the config files in use. It watches their
+// directories rather than the files themselves, so files replaced on save or
+// created after startup are noticed too.
+type configWatcher struct {
+	fsw *fsnotify.Watcher
+
+	mu    sync.Mutex
+	files []string
+}
+
+// newConfigWatcher watches the config and theme files of cfg, or returns nil if the
+// platform can't watch files, in which case changes need a restart
+func newConfigWatcher(cfg config) *configWatcher {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil
+	}
+	w := &configWatcher{fsw: fsw}
+	w.watch(cfg)
+	return w
+}
+
+// watch switches to the config files of cfg, which change with the profile
+// and the folder being practised
+func (w *configWatcher) watch(cfg config) {
+	if w == nil {
+		return
+	}
+	var files []string
+	for _, path := range []string{sharedConfigPath(), configPath(), cfg.RepoConfig, themePath(cfg.Theme)} {
+		if path == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		files = append(files, path)
+		w.fsw.Add(filepath.Dir(path)) // Directories that don't exist yet are picked up after a save
+	}
+
+	w.mu.Lock()
+	w.files = files
+	w.mu.Unlock()
+}
+
+func (w *configWatcher) watching(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Contains(w.files, filepath.Clean(path))
+}
+
+// next waits for the next change to a watched file
+func (w *configWatcher) next() tea.Cmd {
+	if w == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		for {
+			select {
+			case event, ok := <-w.fsw.Events:
+				if !ok {
+					return nil
+				}
+				if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) {
+					continue
+				}
+				if w.watching(event.Name) {
+					w.settle()
+					return configChangedMsg{}
+				}
+			case _, ok := <-w.fsw.Errors:
+				if !ok {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// settle waits until no events have arrived for configSettle
+func (w *configWatcher) settle() {
+	timer := time.NewTimer(configSettle)
+	defer timer.Stop()
+	for {
+		select {
+		case _, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			timer.Reset(configSettle)
+		case <-timer.C:
+			return
+		}
+	}
+}
+
+// reloadConfig applies settings changed on disk to the running session. If
+// a file can't be read the error is shown and the current settings are kept.
+func (m *model) reloadConfig() {
+	before := m.savedConfig()
+	cfg, err := loadConfig()
+	m.configErr = err
+	m.watcher.watch(cfg)
+	if err != nil {
+		return
+	}
+
+	// Challenge settings last for the rest of the session
+	if m.ownSettings != nil {
+		own := cfg
+		m.ownSettings = &own
+		cfg = withChallengeSettings(cfg, m.config)
+	}
+	// A round keeps the rules it started with, new ones apply from the next snippet
+	m.pendingRules = nil
+	if (m.started || m.studying) && !m.finished {
+		pending := cfg
+		m.pendingRules = &pending
+		cfg = withRoundRules(cfg, m.config)
+	}
+	// Compare printed values, as empty lists may be nil on one side only
+	if fmt.Sprint(cfg) == fmt.Sprint(m.config) && m.pendingRules == nil {
+		return // Our own save, or nothing that matters
+	}
+	m.config = cfg
+	m.status = "Settings reloaded from disk"
+	if m.pendingRules != nil {
+		m.status += ", rule changes apply from the next snippet"
+	}
+	if m.showingConfig {
+		m.rebaseDraft(before, m.savedConfig())
+	}
+}
+
+// withRoundRules returns cfg with the settings that define a round, and so
+// its category and result, taken from src
+func withRoundRules(cfg, src config) config {
+	cfg.Mode = src.Mode
+	cfg.MaxTimeLimit = src.MaxTimeLimit
+	cfg.BlindMode = src.BlindMode
+	cfg.MemoryMode = src.MemoryMode
+	cfg.StudySeconds = src.StudySeconds
+	cfg.LockCompletedTokens = src.LockCompletedTokens
+	return cfg
+}
+
+// applyPendingRules starts using rules that changed on disk during the
+// previous round
+func (m *model) applyPendingRules() {
+	if m.pendingRules != nil {
+		m.config = withRoundRules(m.config, *m.pendingRules)
+		m.pendingRules = nil
+	}
+}
+
+// rebaseDraft takes settings changed on disk from before to after into the
+// open settings form. Fields edited in the form keep the edit, and are
+// flagged if the file changed them too.
+func (m *model) rebaseDraft(before, after config) {
+	edited := m.draft
+	for key, ti := range m.settingInputs {
+		settingsSchema[settingIndex(key)].setText(&edited, ti.Value())
+	}
+
+	draft := after
+	for _, s := range settingsSchema {
+		was, now, mine := fmt.Sprint(s.value(&before)), fmt.Sprint(s.value(&after)), fmt.Sprint(s.value(&edited))
+		if mine == was {
+			if ti, ok := m.settingInputs[s.key]; ok {
+				ti.SetValue(s.text(&draft))
+				m.settingInputs[s.key] = ti
+			}
+			continue
+		}
+		s.copyValue(&draft, &edited)
+		if now != was && now != mine {
+			if m.configErrors == nil {
+				m.configErrors = make(map[string]string)
+			}
+			m.configErrors[s.key] = fmt.Sprintf("also changed on disk to %s, saving keeps this value", now)
+		}
+	}
+	m.draft = draft
+}
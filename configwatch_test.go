@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/viper"
+)
+
+// testConfigDir points the config directory at a temporary one with a fresh
+// viper, and returns the shared config file's path
+func testConfigDir(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	return sharedConfigPath()
+}
+
+func TestBookmarkDuringRoundKeepsRulesFromDisk(t *testing.T) {
+	path := testConfigDir(t)
+	write := func(yaml string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("version: 1\nmode: normal\nmax_time_limit: 30\nstudy_seconds: 15\nsource: embedded\n")
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := model{config: cfg, started: true, width: 80, height: 24}
+
+	// The rules change on disk mid-round, and only apply from the next snippet
+	write("version: 1\nmode: sudden-death\nmax_time_limit: 90\nstudy_seconds: 5\nsource: embedded\n")
+	m.reloadConfig()
+	if m.config.Mode != modeNormal || m.pendingRules == nil {
+		t.Fatalf("round rules changed mid-round: mode %q, pending %v", m.config.Mode, m.pendingRules)
+	}
+
+	// Bookmarking in the browser saves the config, which mustn't undo the edit
+	m.openBrowser()
+	snippets, err := indexSnippets(m.config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.showSnippets(snippetsIndexedMsg{gen: m.browserGen, snippets: snippets})
+	if _, err := m.updateBrowser(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")}); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, _, err := readConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]any{"mode": modeSuddenDeath, "max_time_limit": 90, "study_seconds": 5} {
+		if got := saved[key]; got != want {
+			t.Errorf("saved %s = %v, want %v", key, got, want)
+		}
+	}
+	if bookmarks, _ := saved["bookmarks"].([]any); len(bookmarks) != 1 {
+		t.Errorf("saved bookmarks = %v, want one", saved["bookmarks"])
+	}
+
+	// The settings form starts from what's on disk too
+	m.openSettings()
+	if m.draft.Mode != modeSuddenDeath || m.draft.MaxTimeLimit != 90 {
+		t.Errorf("settings draft has mode %q and time %d, want the reloaded rules", m.draft.Mode, m.draft.MaxTimeLimit)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Snippet kinds a corpus can be restricted to
+const (
+	kindFunctions = "functions" // Functions without a receiver
+	kindMethods   = "methods"
+	kindProverbs  = "proverbs" // Go proverbs from the embedded corpus
+)
+
+var snippetKinds = []string{kindFunctions, kindMethods, kindProverbs}
+
+// corpus is one named snippet source in a mixed practice session
+type corpus struct {
+	Name     string   `mapstructure:"name" yaml:"name"`
+	Source   string   `mapstructure:"source" yaml:"source,omitempty"` // Defaults to packages if Packages is set, otherwise folder
+	Path     string   `mapstructure:"path" yaml:"path,omitempty"`
+	Packages []string `mapstructure:"packages" yaml:"packages,omitempty"`
+	Weight   int      `mapstructure:"weight" yaml:"weight,omitempty"` // Relative share of snippets, default 1
+	Ignore   []string `mapstructure:"ignore" yaml:"ignore,omitempty"`
+	Kinds    []string `mapstructure:"kinds" yaml:"kinds,omitempty"`
+}
+
+// config returns cfg with the corpus's source settings in place of the
+// top-level ones
+func (c corpus) config(cfg config) config {
+	cfg.Source = c.Source
+	if cfg.Source == "" && len(c.Packages) > 0 {
+		cfg.Source = sourcePackages
+	}
+	cfg.Source = normalizeSource(cfg.Source)
+	cfg.FolderPath = c.Path
+	cfg.Packages = c.Packages
+	cfg.Ignore = c.Ignore
+	cfg.Kinds = c.Kinds
+	cfg.Corpora = nil
+	return cfg
+}
+
+func (c corpus) weight() int {
+	return max(c.Weight, 1)
+}
+
+// corpusConfigs returns one config per configured corpus, or cfg itself when
+// no corpora are configured
+func corpusConfigs(cfg config) []config {
+	if len(cfg.Corpora) == 0 {
+		return []config{cfg}
+	}
+	configs := make([]config, len(cfg.Corpora))
+	for i, c := range cfg.Corpora {
+		configs[i] = c.config(cfg)
+	}
+	return configs
+}
+
+// loadRandomFunction picks a snippet, first choosing a corpus in proportion
+// to its weight. Corpora without a suitable snippet are skipped.
+func loadRandomFunction(cfg config, rng *rand.Rand) (string, string, error) {
+	if len(cfg.Corpora) == 0 {
+		return loadCorpusFunction(cfg, rng)
+	}
+
+	remaining := slices.Clone(cfg.Corpora)
+	var errs []error
+	for len(remaining) > 0 {
+		total := 0
+		for _, c := range remaining {
+			total += c.weight()
+		}
+		pick := rng.Intn(total)
+		i := 0
+		for pick >= remaining[i].weight() {
+			pick -= remaining[i].weight()
+			i++
+		}
+
+		corpusCfg := remaining[i].config(cfg)
+		text, file, err := loadCorpusFunction(corpusCfg, rng)
+		if err == nil && isFallback(corpusCfg, file) {
+			err = fmt.Errorf("no Go files found in %s", corpusCfg.FolderPath)
+		}
+		if err == nil {
+			return text, file, nil
+		}
+		errs = append(errs, fmt.Errorf("corpus %s: %w", remaining[i].Name, err))
+		remaining = slices.Delete(remaining, i, i+1)
+	}
+	return "", "", errors.Join(errs...)
+}
+
+// isFallback reports whether file came from the built-in corpus standing in
+// for an empty folder. Only a lone folder falls back, corpora are skipped.
+func isFallback(cfg config, file string) bool {
+	return strings.HasPrefix(file, embeddedPrefix) && cfg.Source != sourceEmbedded
+}
+
+// checkKinds rejects snippet kinds that don't exist
+func checkKinds(kinds []string) error {
+	for _, kind := range kinds {
+		if !slices.Contains(snippetKinds, kind) {
+			return fmt.Errorf("unknown snippet kind %q, want %s", kind, strings.Join(snippetKinds, ", "))
+		}
+	}
+	return nil
+}
+
+// wantKind reports whether a snippet kind is allowed, where no kinds means all
+func wantKind(kinds []string, kind string) bool {
+	return len(kinds) == 0 || slices.Contains(kinds, kind)
+}
+
+// ignored reports whether a file matches any ignore pattern. Patterns
+// containing a slash match the path relative to the corpus root or a
+// leading part of it, others match any single file or directory name, so
+// "vendor", "*_test.go" and "internal/legacy" all work as expected.
+func ignored(rel string, patterns []string) bool {
+	rel = filepath.ToSlash(rel)
+	parts := strings.Split(rel, "/")
+	for _, pattern := range patterns {
+		pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+		if strings.Contains(pattern, "/") {
+			depth := strings.Count(pattern, "/") + 1
+			if depth <= len(parts) {
+				if ok, _ := filepath.Match(pattern, strings.Join(parts[:depth], "/")); ok {
+					return true
+				}
+			}
+			continue
+		}
+		for _, part := range parts {
+			if ok, _ := filepath.Match(pattern, part); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// corporaSummary lists corpus names with their share of snippets, e.g.
+// "services 60%"
+func corporaSummary(corpora []corpus) []string {
+	total := 0
+	for _, c := range corpora {
+		total += c.weight()
+	}
+	summary := make([]string, len(corpora))
+	for i, c := range corpora {
+		summary[i] = c.Name + " " + strconv.Itoa(c.weight()*100/total) + "%"
+	}
+	return summary
+}
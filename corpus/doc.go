@@ -0,0 +1,4 @@
+// Package corpus holds the curated snippets embedded into typing-vibes as an
+// offline fallback. It is compiled and vetted with the rest of the module so
+// every snippet stays valid Go, but nothing imports it.
+package corpus
@@ -0,0 +1,73 @@
+package corpus
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+type Shape interface {
+	Area() float64
+	Perimeter() float64
+}
+
+type Rect struct {
+	Width, Height float64
+}
+
+func (r Rect) Area() float64 {
+	return r.Width * r.Height
+}
+
+func (r Rect) Perimeter() float64 {
+	return 2 * (r.Width + r.Height)
+}
+
+type byArea []Shape
+
+func (s byArea) Len() int           { return len(s) }
+func (s byArea) Less(i, j int) bool { return s[i].Area() < s[j].Area() }
+func (s byArea) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+func Largest(shapes []Shape) Shape {
+	if len(shapes) == 0 {
+		return nil
+	}
+	sorted := append(byArea(nil), shapes...)
+	sort.Sort(sort.Reverse(sorted))
+	return sorted[0]
+}
+
+type upperWriter struct {
+	w io.Writer
+}
+
+func (u upperWriter) Write(p []byte) (int, error) {
+	return u.w.Write([]byte(strings.ToUpper(string(p))))
+}
+
+func Describe(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return "string of length " + itoa(len(v))
+	case interface{ String() string }:
+		return "stringer: " + v.String()
+	case error:
+		return "error: " + v.Error()
+	default:
+		return "something else"
+	}
+}
+
+func itoa(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	var digits []byte
+	for ; n > 0; n /= 10 {
+		digits = append([]byte{byte('0' + n%10)}, digits...)
+	}
+	return string(digits)
+}
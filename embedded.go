@@ -0,0 +1,76 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"strings"
+)
+
+// The curated fallback corpus, compiled as its own package so the snippets
+// are always valid Go
+//
+//go:embed corpus/*.go corpus/proverbs.txt
+var embeddedCorpus embed.FS
+
+// embeddedPrefix marks file paths that live in the embedded corpus
+const embeddedPrefix = "embedded:"
+
+// loadEmbeddedFunction picks a function within the line bounds or a proverb
+// from the embedded corpus
+func loadEmbeddedFunction(cfg config, rng *rand.Rand) (string, string, error) {
+	type candidate struct {
+		text string
+		file string
+	}
+	var valid []candidate
+
+	files, err := embeddedGoFiles()
+	if err != nil {
+		return "", "", err
+	}
+	for _, file := range files {
+		functions, err := extractFunctions(file)
+		if err != nil {
+			return "", "", err
+		}
+		for _, fn := range functions {
+			if !wantKind(cfg.Kinds, fn.Kind()) {
+				continue
+			}
+			if lines := countLines(fn.Text); lines >= cfg.MinLines && lines <= cfg.MaxLines {
+				valid = append(valid, candidate{text: fn.Text, file: file})
+			}
+		}
+	}
+
+	// Proverbs are single lines, so they are always eligible
+	if wantKind(cfg.Kinds, kindProverbs) {
+		proverbs, err := embeddedCorpus.ReadFile("corpus/proverbs.txt")
+		if err != nil {
+			return "", "", err
+		}
+		for _, proverb := range strings.Split(strings.TrimSpace(string(proverbs)), "\n") {
+			valid = append(valid, candidate{text: proverb, file: embeddedPrefix + "corpus/proverbs.txt"})
+		}
+	}
+	if len(valid) == 0 {
+		return "", "", fmt.Errorf("no built-in snippets between %d and %d lines", cfg.MinLines, cfg.MaxLines)
+	}
+
+	picked := valid[rng.Intn(len(valid))]
+	return picked.text, picked.file, nil
+}
+
+// embeddedGoFiles lists the embedded snippet files as prefixed paths
+func embeddedGoFiles() ([]string, error) {
+	files, err := fs.Glob(embeddedCorpus, "corpus/*.go")
+	if err != nil {
+		return nil, err
+	}
+	for i, file := range files {
+		files[i] = embeddedPrefix + file
+	}
+	return files, nil
+}
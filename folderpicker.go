@@ -0,0 +1,284 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxCountEntries bounds how much of a directory tree is walked to count its
+// Go files, so huge trees like $HOME don't stall the picker
+const maxCountEntries = 5000
+
+// folderEntry is one row of the folder picker
+type folderEntry struct {
+	name    string // "." for the directory being browsed
+	goFiles int
+	capped  bool // The count stopped at maxCountEntries
+	counted bool
+}
+
+// folderPicker browses directories to choose the Folder Path setting
+type folderPicker struct {
+	dir     string
+	entries []folderEntry
+	cursor  int
+	err     error
+	gen     int // Bumped on each open so counts for a previous listing are ignored
+}
+
+// folderCountMsg carries the Go file count of one picker entry
+type folderCountMsg struct {
+	gen     int
+	index   int
+	goFiles int
+	capped  bool
+}
+
+// openFolderPicker starts browsing at the folder path setting, or the
+// nearest directory above it that exists
+func (m *model) openFolderPicker() tea.Cmd {
+	dir, err := expandHome(m.settingInputs["folder_path"].Value())
+	if err != nil || dir == "" {
+		dir, _ = os.UserHomeDir()
+	}
+	dir, _ = filepath.Abs(dir)
+	for {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	m.showingPicker = true
+	return m.picker.open(dir)
+}
+
+// open lists dir's subdirectories, returning a command that counts their Go
+// files in the background
+func (p *folderPicker) open(dir string) tea.Cmd {
+	p.dir = dir
+	p.cursor = 0
+	p.entries = []folderEntry{{name: "."}}
+	p.gen++
+
+	dirEntries, err := os.ReadDir(dir)
+	p.err = err
+	for _, entry := range dirEntries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			p.entries = append(p.entries, folderEntry{name: entry.Name()})
+		}
+	}
+	return p.count(0)
+}
+
+// count counts the Go files of entry i. Entries are counted one at a time
+// so a big listing doesn't walk every subtree at once.
+func (p folderPicker) count(i int) tea.Cmd {
+	if i >= len(p.entries) {
+		return nil
+	}
+	gen, path := p.gen, filepath.Join(p.dir, p.entries[i].name)
+	return func() tea.Msg {
+		goFiles, capped := countGoFiles(path)
+		return folderCountMsg{gen: gen, index: i, goFiles: goFiles, capped: capped}
+	}
+}
+
+// setCount records an entry's count and moves on to the next entry
+func (p *folderPicker) setCount(msg folderCountMsg) tea.Cmd {
+	if msg.gen != p.gen {
+		return nil
+	}
+	entry := &p.entries[msg.index]
+	entry.goFiles, entry.capped, entry.counted = msg.goFiles, msg.capped, true
+	return p.count(msg.index + 1)
+}
+
+// selected returns the path of the highlighted row
+func (p folderPicker) selected() string {
+	return filepath.Join(p.dir, p.entries[p.cursor].name)
+}
+
+// errCountCapped stops the walk in countGoFiles after maxCountEntries entries
+var errCountCapped = errors.New("count capped")
+
+// countGoFiles counts the Go files under dir, skipping hidden directories
+func countGoFiles(dir string) (int, bool) {
+	count, visited := 0, 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip files we can't access
+		}
+		if visited++; visited > maxCountEntries {
+			return errCountCapped
+		}
+		if d.IsDir() && path != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".go") {
+			count++
+		}
+		return nil
+	})
+	return count, err == errCountCapped
+}
+
+// updateFolderPicker handles keys while the folder picker is open
+func (m model) updateFolderPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	p := &m.picker
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.showingPicker = false
+
+	case tea.KeyUp:
+		p.cursor = max(p.cursor-1, 0)
+
+	case tea.KeyDown:
+		p.cursor = min(p.cursor+1, len(p.entries)-1)
+
+	case tea.KeyRight:
+		if p.cursor > 0 {
+			cmd = p.open(p.selected())
+		}
+
+	case tea.KeyLeft, tea.KeyBackspace:
+		if parent := filepath.Dir(p.dir); parent != p.dir {
+			child := filepath.Base(p.dir)
+			cmd = p.open(parent)
+			for i, entry := range p.entries {
+				if entry.name == child {
+					p.cursor = i
+				}
+			}
+		}
+
+	case tea.KeyEnter:
+		ti := m.settingInputs["folder_path"]
+		ti.SetValue(contractHome(p.selected()))
+		ti.CursorEnd()
+		m.settingInputs["folder_path"] = ti
+		delete(m.configErrors, "folder_path")
+		m.showingPicker = false
+	}
+	return m, cmd
+}
+
+// contractHome replaces the user's home directory with ~
+func contractHome(path string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil || homeDir == "" {
+		return path
+	}
+	if path == homeDir {
+		return "~"
+	}
+	if rest, ok := strings.CutPrefix(path, homeDir+string(filepath.Separator)); ok {
+		return filepath.Join("~", rest)
+	}
+	return path
+}
+
+// completePath extends a typed directory path to the longest unambiguous
+// match, keeping a leading ~. It returns false if there's nothing to add.
+func completePath(typed string) (string, bool) {
+	expanded, err := expandHome(typed)
+	if err != nil || expanded == "" {
+		return typed, false
+	}
+	dir, prefix := filepath.Split(expanded)
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return typed, false
+	}
+	var matches []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		matches = append(matches, name)
+	}
+	if len(matches) == 0 {
+		return typed, false
+	}
+
+	completion := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, completion) {
+			completion = completion[:len(completion)-1]
+		}
+	}
+	if len(matches) == 1 {
+		completion += string(filepath.Separator)
+	}
+	if completion == prefix {
+		return typed, false
+	}
+	return typed + completion[len(prefix):], true
+}
+
+func (m model) renderFolderPicker() string {
+	var b strings.Builder
+	p := m.picker
+
+	b.WriteString(titleStyle.Render("📁 Choose Folder"))
+	b.WriteString("\n\n")
+	b.WriteString(formLabelStyle.Render(contractHome(p.dir)))
+	b.WriteString("\n\n")
+	if p.err != nil {
+		b.WriteString(errorStyle.Render(p.err.Error()))
+		b.WriteString("\n\n")
+	}
+
+	// Keep the cursor in view
+	visible := max(m.height-9, 3)
+	start := max(0, min(p.cursor-visible/2, len(p.entries)-visible))
+	end := min(start+visible, len(p.entries))
+
+	for i := start; i < end; i++ {
+		entry := p.entries[i]
+		name := entry.name + "/"
+		if entry.name == "." {
+			name = ". (this folder)"
+		}
+		count := fmt.Sprintf("%d Go files", entry.goFiles)
+		switch {
+		case !entry.counted:
+			count = "counting…"
+		case entry.capped:
+			count = fmt.Sprintf("%d+ Go files", entry.goFiles)
+		}
+
+		line := fmt.Sprintf("%-40s %s", name, count)
+		switch {
+		case i == p.cursor:
+			b.WriteString(valueStyle.Render("▸ " + line))
+		case !entry.counted, entry.goFiles == 0:
+			b.WriteString(labelStyle.Render("  " + line))
+		default:
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpStyle.Render("↑/↓ to move • → to open • ← to go up • Enter to choose • Esc to cancel"))
+	return b.String()
+}
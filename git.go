@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The well-known hash of git's empty tree, used as a base when history is
+// shorter than the requested window
+const gitEmptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// lineRange is an inclusive range of 1-based line numbers
+type lineRange struct {
+	Start int
+	End   int
+}
+
+// changedLines maps absolute file paths to the line ranges touched in them
+type changedLines map[string][]lineRange
+
+// overlaps reports whether any changed range in path intersects [start, end]
+func (c changedLines) overlaps(path string, start, end int) bool {
+	for _, r := range c[path] {
+		if r.Start <= end && r.End >= start {
+			return true
+		}
+	}
+	return false
+}
+
+// runGit runs a git command inside dir and returns its trimmed stdout
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// gitRecentChanges returns the Go lines under dir that changed within the
+// configured window of days or commits, or in all of history if neither is
+// set, optionally restricted to lines that
+// also differ from the configured base branch
+func gitRecentChanges(dir string, cfg config) (changedLines, error) {
+	var base string
+	var err error
+	switch {
+	case cfg.GitRecentCommits > 0:
+		base, err = runGit(dir, "rev-parse", "--verify", "-q", fmt.Sprintf("HEAD~%d", cfg.GitRecentCommits))
+	case cfg.GitRecentDays > 0:
+		base, err = runGit(dir, "rev-list", "-1", fmt.Sprintf("--before=%d days ago", cfg.GitRecentDays), "HEAD")
+	}
+	if err != nil || base == "" {
+		// No window, or history doesn't reach back that far, so everything counts as recent
+		if _, headErr := runGit(dir, "rev-parse", "HEAD"); headErr != nil {
+			return nil, fmt.Errorf("%s is not inside a git repository with commits", dir)
+		}
+		base = gitEmptyTree
+	}
+
+	changed, err := gitDiffLines(dir, base)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.GitBaseBranch != "" {
+		mergeBase, err := runGit(dir, "merge-base", "HEAD", cfg.GitBaseBranch)
+		if err != nil {
+			return nil, err
+		}
+		branchChanged, err := gitDiffLines(dir, mergeBase)
+		if err != nil {
+			return nil, err
+		}
+		changed = intersectChanges(changed, branchChanged)
+	}
+
+	return changed, nil
+}
+
+// gitDiffLines diffs the working tree under dir against base and collects
+// the new-side line ranges of every hunk in Go files
+func gitDiffLines(dir, base string) (changedLines, error) {
+	out, err := runGit(dir, "diff", "-U0", "--no-color", "--no-ext-diff", "--relative", base, "--", "*.go")
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(changedLines)
+	var current string
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			current = ""
+			if path, ok := strings.CutPrefix(line, "+++ b/"); ok {
+				current = filepath.Join(dir, path)
+			}
+		case strings.HasPrefix(line, "@@ ") && current != "":
+			if r, ok := parseHunkHeader(line); ok {
+				changed[current] = append(changed[current], r)
+			}
+		}
+	}
+	return changed, scanner.Err()
+}
+
+// parseHunkHeader extracts the new-side range from "@@ -a,b +c,d @@".
+// Pure deletions have no new lines, so the line they precede is used.
+func parseHunkHeader(header string) (lineRange, bool) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return lineRange{}, false
+	}
+
+	startText, countText, hasCount := strings.Cut(fields[2][1:], ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return lineRange{}, false
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return lineRange{}, false
+		}
+	}
+
+	if count == 0 {
+		return lineRange{Start: start, End: start + 1}, true
+	}
+	return lineRange{Start: start, End: start + count - 1}, true
+}
+
+// intersectChanges keeps only the line ranges present in both sets
+func intersectChanges(a, b changedLines) changedLines {
+	result := make(changedLines)
+	for path, aRanges := range a {
+		for _, ar := range aRanges {
+			for _, br := range b[path] {
+				start, end := max(ar.Start, br.Start), min(ar.End, br.End)
+				if start <= end {
+					result[path] = append(result[path], lineRange{Start: start, End: end})
+				}
+			}
+		}
+	}
+	return result
+}
+
+// gitBlameAuthors returns the author email of every line in path, in order
+func gitBlameAuthors(path string) ([]string, error) {
+	out, err := runGit(filepath.Dir(path), "blame", "--line-porcelain", "--", filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var authors []string
+	for _, line := range strings.Split(out, "\n") {
+		if mail, ok := strings.CutPrefix(line, "author-mail "); ok {
+			authors = append(authors, strings.Trim(mail, "<>"))
+		}
+	}
+	return authors, nil
+}
+
+// mostlyAuthoredBy reports whether the majority of lines start..end
+// (1-based, inclusive) are attributed to email
+func mostlyAuthoredBy(authors []string, start, end int, email string) bool {
+	total, matching := 0, 0
+	for line := start; line <= end && line <= len(authors); line++ {
+		total++
+		if strings.EqualFold(authors[line-1], email) {
+			matching++
+		}
+	}
+	return total > 0 && matching*2 > total
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHunkHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   lineRange
+		ok     bool
+	}{
+		{"@@ -1,2 +3,4 @@", lineRange{Start: 3, End: 6}, true},
+		{"@@ -10 +12 @@ func main() {", lineRange{Start: 12, End: 12}, true},
+		{"@@ -5,3 +4,0 @@", lineRange{Start: 4, End: 5}, true}, // Pure deletion
+		{"@@ -0,0 +1,7 @@", lineRange{Start: 1, End: 7}, true}, // New file
+		{"@@ -1,2 @@", lineRange{}, false},
+		{"@@ -1,2 3,4 @@", lineRange{}, false},
+		{"@@ -1,2 +x,4 @@", lineRange{}, false},
+		{"@@ -1,2 +3,y @@", lineRange{}, false},
+		{"", lineRange{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseHunkHeader(tt.header)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseHunkHeader(%q) = %v, %v, want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestIntersectChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b changedLines
+		want changedLines
+	}{
+		{
+			name: "overlap",
+			a:    changedLines{"a.go": {{Start: 1, End: 10}}},
+			b:    changedLines{"a.go": {{Start: 5, End: 20}}},
+			want: changedLines{"a.go": {{Start: 5, End: 10}}},
+		},
+		{
+			name: "touching ends",
+			a:    changedLines{"a.go": {{Start: 1, End: 5}}},
+			b:    changedLines{"a.go": {{Start: 5, End: 9}}},
+			want: changedLines{"a.go": {{Start: 5, End: 5}}},
+		},
+		{
+			name: "disjoint",
+			a:    changedLines{"a.go": {{Start: 1, End: 4}}},
+			b:    changedLines{"a.go": {{Start: 5, End: 9}}},
+			want: changedLines{},
+		},
+		{
+			name: "different files",
+			a:    changedLines{"a.go": {{Start: 1, End: 10}}},
+			b:    changedLines{"b.go": {{Start: 1, End: 10}}},
+			want: changedLines{},
+		},
+		{
+			name: "several ranges",
+			a:    changedLines{"a.go": {{Start: 1, End: 3}, {Start: 10, End: 12}}},
+			b:    changedLines{"a.go": {{Start: 2, End: 11}}},
+			want: changedLines{"a.go": {{Start: 2, End: 3}, {Start: 10, End: 11}}},
+		},
+		{
+			name: "empty",
+			a:    changedLines{},
+			b:    changedLines{"a.go": {{Start: 1, End: 10}}},
+			want: changedLines{},
+		},
+	}
+	for _, tt := range tests {
+		if got := intersectChanges(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: intersectChanges = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
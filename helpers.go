@@ -9,6 +9,17 @@ func countLines(text string) int {
 	return len(strings.Split(strings.TrimSpace(text), "\n"))
 }
 
+// splitList parses a comma separated list, dropping empty entries
+func splitList(text string) []string {
+	var items []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // isCharacterCorrect checks if the character about to be typed matches the expected character
 func isCharacterCorrect(currentInput, target, charToAdd string) bool {
 	// Get position accounting for skipped leading whitespace
@@ -92,6 +103,31 @@ func getCurrentPosition(currentInput, target string) int {
 	return targetPos
 }
 
+// alignInput returns the target position matched by each input rune,
+// skipping leading whitespace in the target the same way rendering does
+func alignInput(currentInput, target string) []int {
+	targetRunes := []rune(target)
+	inputLen := len([]rune(currentInput))
+	positions := make([]int, 0, inputLen)
+	atLineStart := true
+
+	for targetPos := 0; targetPos < len(targetRunes) && len(positions) < inputLen; targetPos++ {
+		targetChar := targetRunes[targetPos]
+		isLeadingWhitespace := atLineStart && (targetChar == ' ' || targetChar == '\t')
+
+		if !isLeadingWhitespace {
+			positions = append(positions, targetPos)
+			atLineStart = false
+		}
+
+		if targetChar == '\n' {
+			atLineStart = true
+		}
+	}
+
+	return positions
+}
+
 func disableLigatures(text string) string {
 	// Insert zero-width space (U+200B) between characters that form ligatures
 	// This prevents the terminal from rendering them as ligatures
@@ -168,4 +204,3 @@ func calculateAccuracyFromCounters(correctChars, incorrectChars int) float64 {
 	}
 	return float64(correctChars) / float64(total) * 100
 }
-
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Round outcomes recorded in history
+const (
+	outcomeCompleted = "completed"
+	outcomeTimeout   = "timeout"
+	outcomeFailed    = "failed" // Sudden death ended the run
+)
+
+// result is a single finished round as stored in the history file
+type result struct {
+	Timestamp      time.Time `json:"timestamp"`
+	File           string    `json:"file"`
+	Category       string    `json:"category"` // The mode the round was played in
+	Blind          bool      `json:"blind,omitempty"`
+	Memory         bool      `json:"memory,omitempty"`
+	Daily          string    `json:"daily,omitempty"`          // Date of the daily challenge played
+	Challenge      string    `json:"challenge,omitempty"`      // Code of the shared challenge played
+	Preset         string    `json:"preset,omitempty"`         // Preset the round was played with
+	PeekedPercent  float64   `json:"peeked_percent,omitempty"` // Share of the snippet revealed with peek
+	Outcome        string    `json:"outcome"`
+	WPM            float64   `json:"wpm"`
+	Accuracy       float64   `json:"accuracy"`
+	Seconds        float64   `json:"seconds"`
+	CorrectChars   int       `json:"correct_chars"`
+	IncorrectChars int       `json:"incorrect_chars"`
+	Restarts       int       `json:"restarts,omitempty"`
+}
+
+func historyPath() string {
+	return filepath.Join(profileStateDir(), "history.jsonl")
+}
+
+// migrateHistory moves history from where older versions kept it, the data
+// directory or before that the config directory, to the state directory
+func migrateHistory() error {
+	newPath := historyPath()
+	if _, err := os.Stat(newPath); !os.IsNotExist(err) {
+		return nil
+	}
+	for _, oldPath := range []string{
+		filepath.Join(profileDir(legacyDataDir()), "history.jsonl"),
+		filepath.Join(profileConfigDir(), "history.jsonl"),
+	} {
+		if oldPath == newPath {
+			continue
+		}
+		if _, err := os.Stat(oldPath); err != nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+			return err
+		}
+		return moveFile(oldPath, newPath)
+	}
+	return nil
+}
+
+// moveFile renames a file, copying it when that isn't possible, e.g. across
+// filesystems
+func moveFile(oldPath, newPath string) error {
+	if err := os.Rename(oldPath, newPath); err == nil {
+		return nil
+	}
+	data, err := os.ReadFile(oldPath)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(newPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Remove(oldPath)
+}
+
+// appendResult adds a result to the history file, one JSON object per line
+func appendResult(r result) error {
+	if err := migrateHistory(); err != nil {
+		return err
+	}
+	path := historyPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// loadHistory reads all recorded results, skipping lines that fail to parse
+func loadHistory() ([]result, error) {
+	if err := migrateHistory(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(historyPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var results []result
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r result
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		results = append(results, r)
+	}
+	return results, scanner.Err()
+}
+
+// categoryLabel combines the mode with any display variations, so results
+// are only compared like-for-like
+func (r result) categoryLabel() string {
+	label := r.Category
+	if r.Blind {
+		label += "+blind"
+	}
+	if r.Memory {
+		label += "+memory"
+	}
+	if r.Daily != "" {
+		label += "+daily"
+	}
+	if r.Preset != "" {
+		label = r.Preset + ":" + label
+	}
+	return label
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startStudy shows the whole snippet before it is hidden for memory mode
+func (m *model) startStudy() tea.Cmd {
+	if !m.config.MemoryMode {
+		return nil
+	}
+	m.studying = true
+	m.studyStart = time.Now()
+	if m.config.StudySeconds > 0 {
+		return tickCmd()
+	}
+	return nil
+}
+
+// studyRemaining returns how long the study period has left, if it is timed
+func (m model) studyRemaining() time.Duration {
+	studyDuration := time.Duration(m.config.StudySeconds) * time.Second
+	remaining := studyDuration - time.Since(m.studyStart)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// peek reveals the rest of the current line in memory mode
+func (m *model) peek() {
+	targetRunes := []rune(m.targetText)
+	for pos := getCurrentPosition(m.currentInput, m.targetText); pos < len(targetRunes) && targetRunes[pos] != '\n'; pos++ {
+		m.peeked[pos] = true
+	}
+}
+
+// peekedPercent is the share of non-whitespace target characters revealed by peeking
+func (m model) peekedPercent() float64 {
+	total, peeked := 0, 0
+	for pos, char := range []rune(m.targetText) {
+		if char == ' ' || char == '\t' || char == '\n' {
+			continue
+		}
+		total++
+		if m.peeked[pos] {
+			peeked++
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(peeked) / float64(total) * 100
+}
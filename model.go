@@ -2,8 +2,11 @@ package main
 
 import (
 	"fmt"
+	"math/rand"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -24,11 +27,46 @@ type model struct {
 	err            error
 	config         config
 	showingConfig  bool
-	configInputs   []textinput.Model
+	draft          config                     // Settings being edited
+	settingInputs  map[string]textinput.Model // Text and path settings by viper key
 	focusIndex     int
+	settingsScroll int
 	correctChars   int          // Track correct characters typed
 	incorrectChars int          // Track incorrect characters typed (even if corrected)
 	errorPositions map[int]bool // Track positions where errors occurred
+	outcome        string       // How the finished round ended
+	restarts       int          // Perfectionist restarts of the current snippet
+	status         string       // Transient message shown under the panes
+	invalidReason  string       // Why the round's result won't be recorded
+	lastKeyTime    time.Time    // When the previous typing keystroke arrived
+	fastKeys       int          // Consecutive keystrokes faster than a human types
+	studying       bool         // Memory mode study period before typing
+	studyStart     time.Time
+	peeked         map[int]bool // Target positions revealed by peeking in memory mode
+	showingBrowser bool
+	browser        list.Model
+	browserGen     int        // Bumped on each open and close so stale indexing results are dropped
+	rng            *rand.Rand // Snippet selection source, seeded for reproducible runs
+	dailyDate      string     // Set when playing the daily challenge
+	challenge      string     // Code of the challenge being played, if any
+	ownSettings    *config    // The user's settings while a challenge's apply, nil if none do
+	pendingRules   *config    // Settings reloaded during a round, whose rules apply from the next one
+
+	bests map[string]float64 // Personal best WPM by category for the profile
+
+	configErrors  map[string]string // Settings validation errors by viper key
+	showingPicker bool              // Folder picker opened from the settings form
+	picker        folderPicker
+
+	showingChallengePrompt bool
+	challengeInput         textinput.Model
+
+	configErr error          // Why a config file couldn't be read, shown until it's fixed
+	watcher   *configWatcher // Reloads settings when config files change, nil if unsupported
+
+	syntax []syntaxClass // Token class of each target rune, nil if it isn't Go
+
+	backspaceIsDEL bool // Backspace arrived as DEL, so Ctrl+H is Ctrl+Backspace
 }
 
 func initialModel() model {
@@ -38,43 +76,139 @@ func initialModel() model {
 	ti.CharLimit = 10000
 	ti.Width = 80
 
-	cfg := loadConfig()
-
-	// Create config form inputs
-	inputs := make([]textinput.Model, 4)
-
-	inputs[0] = textinput.New()
-	inputs[0].Placeholder = "Folder path"
-	inputs[0].SetValue(cfg.FolderPath)
-	inputs[0].Width = 50
-
-	inputs[1] = textinput.New()
-	inputs[1].Placeholder = "Minimum lines"
-	inputs[1].SetValue(fmt.Sprintf("%d", cfg.MinLines))
-	inputs[1].Width = 20
+	cfg, configErr := loadConfig()
 
-	inputs[2] = textinput.New()
-	inputs[2].Placeholder = "Maximum lines"
-	inputs[2].SetValue(fmt.Sprintf("%d", cfg.MaxLines))
-	inputs[2].Width = 20
-
-	inputs[3] = textinput.New()
-	inputs[3].Placeholder = "Max time (0 = no limit)"
-	inputs[3].SetValue(fmt.Sprintf("%d", cfg.MaxTimeLimit))
-	inputs[3].Width = 20
+	seed, dailyDate := selectionSeed(cfg)
 
 	return model{
+		rng:            newSelectionRand(seed),
+		dailyDate:      dailyDate,
 		textInput:      ti,
 		config:         cfg,
+		configErr:      configErr,
+		watcher:        newConfigWatcher(cfg),
 		width:          120,
 		height:         24,
-		configInputs:   inputs,
 		errorPositions: make(map[int]bool),
+		peeked:         make(map[int]bool),
+		bests:          loadPersonalBests(),
+	}
+}
+
+// loadNextFunction picks a new random function and resets the round
+func (m *model) loadNextFunction() tea.Cmd {
+	m.applyPendingRules()
+	funcText, filePath, err := loadRandomFunction(m.config, m.rng)
+	if err != nil {
+		m.err = err
+		return nil
+	}
+	m.err = nil
+	m.challenge = ""
+	m.setSnippet(funcText, filePath)
+	m.resetRound()
+	if strings.HasPrefix(filePath, embeddedPrefix) && m.config.Source != sourceEmbedded {
+		m.status = fmt.Sprintf("No Go files found in %s, practising the built-in corpus instead", m.config.FolderPath)
+	}
+	return m.startStudy()
+}
+
+// setSnippet makes text from file the target, classifying Go code for
+// highlighting once rather than on every render
+func (m *model) setSnippet(text, file string) {
+	m.targetText = text
+	m.currentFile = file
+	m.syntax = nil
+	if strings.HasSuffix(file, ".go") {
+		m.syntax = syntaxClasses([]rune(text))
 	}
 }
 
+// resetRound clears all typing progress for the current target
+func (m *model) resetRound() {
+	m.currentInput = ""
+	m.started = false
+	m.finished = false
+	m.correctChars = 0
+	m.incorrectChars = 0
+	m.errorPositions = make(map[int]bool)
+	m.outcome = ""
+	m.restarts = 0
+	m.status = ""
+	m.invalidReason = ""
+	m.lastKeyTime = time.Time{}
+	m.fastKeys = 0
+	m.studying = false
+	m.peeked = make(map[int]bool)
+}
+
+// finish ends the round and records the result in history unless the
+// round was flagged as invalid
+func (m *model) finish(outcome string, endTime time.Time) {
+	m.finished = true
+	m.outcome = outcome
+	m.endTime = endTime
+	if m.invalidReason != "" {
+		return
+	}
+
+	elapsed := m.endTime.Sub(m.startTime)
+	r := result{
+		Timestamp:      m.endTime,
+		File:           m.currentFile,
+		Category:       m.config.Mode,
+		Blind:          m.config.BlindMode,
+		Memory:         m.config.MemoryMode,
+		Daily:          m.dailyDate,
+		Challenge:      m.challenge,
+		Preset:         m.config.Preset,
+		Outcome:        outcome,
+		WPM:            calculateWPM(m.currentInput, elapsed),
+		Accuracy:       calculateAccuracyFromCounters(m.correctChars, m.incorrectChars),
+		Seconds:        elapsed.Seconds(),
+		CorrectChars:   m.correctChars,
+		IncorrectChars: m.incorrectChars,
+		Restarts:       m.restarts,
+	}
+	if m.config.MemoryMode {
+		r.PeekedPercent = m.peekedPercent()
+	}
+	if err := appendResult(r); err != nil {
+		m.status = fmt.Sprintf("Could not save result: %v", err)
+		return
+	}
+
+	label := r.categoryLabel()
+	if outcome == outcomeCompleted && r.WPM > m.bests[label] {
+		if previous := m.bests[label]; previous > 0 {
+			m.status = fmt.Sprintf("🏆 New personal best for %s: %.0f WPM (was %.0f)", label, r.WPM, previous)
+		} else {
+			m.status = fmt.Sprintf("🏆 First personal best for %s: %.0f WPM", label, r.WPM)
+		}
+		m.bests[label] = r.WPM
+	}
+}
+
+// categoryLabel is the stats category the current round is recorded under
+func (m model) categoryLabel() string {
+	r := result{
+		Category: m.config.Mode,
+		Blind:    m.config.BlindMode,
+		Memory:   m.config.MemoryMode,
+		Daily:    m.dailyDate,
+		Preset:   m.config.Preset,
+	}
+	return r.categoryLabel()
+}
+
 func (m model) Init() tea.Cmd {
-	return textinput.Blink
+	cmds := []tea.Cmd{textinput.Blink, m.watcher.next()}
+	// A snippet loaded before the program started, by the file or challenge
+	// play commands, may already be in a timed study period
+	if m.studying && m.config.StudySeconds > 0 {
+		cmds = append(cmds, tickCmd())
+	}
+	return tea.Batch(cmds...)
 }
 
 func tickCmd() tea.Cmd {
@@ -82,4 +216,3 @@ func tickCmd() tea.Cmd {
 		return tickMsg(t)
 	})
 }
-
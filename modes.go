@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// Challenge modes change what happens when an incorrect key is typed
+const (
+	modeNormal        = "normal"
+	modeSuddenDeath   = "sudden-death"  // Run ends on the first mistake
+	modePerfectionist = "perfectionist" // Snippet restarts from scratch on any mistake
+)
+
+var modes = []string{modeNormal, modeSuddenDeath, modePerfectionist}
+
+// normalizeMode maps user input to a known mode, falling back to normal
+func normalizeMode(mode string) string {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	mode = strings.ReplaceAll(mode, "_", "-")
+	for _, known := range modes {
+		if mode == known {
+			return known
+		}
+	}
+	return modeNormal
+}
+
+// handleMistake applies the active mode's penalty after an incorrect keystroke
+// has been recorded. It returns true if the round was ended or restarted.
+func (m *model) handleMistake() bool {
+	switch m.config.Mode {
+	case modeSuddenDeath:
+		if !m.started {
+			m.started = true
+			m.startTime = time.Now()
+		}
+		m.finish(outcomeFailed, time.Now())
+		return true
+	case modePerfectionist:
+		restarts, peeked := m.restarts+1, m.peeked
+		m.resetRound()
+		m.restarts, m.peeked = restarts, peeked
+		return true
+	}
+	return false
+}
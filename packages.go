@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// goEnv reads GOROOT, GOMODCACHE and GOVERSION from the local toolchain once
+var goEnv = sync.OnceValues(func() (map[string]string, error) {
+	keys := []string{"GOROOT", "GOMODCACHE", "GOVERSION"}
+	out, err := exec.Command("go", append([]string{"env"}, keys...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("go env: %w", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if len(lines) != len(keys) {
+		return nil, fmt.Errorf("go env: unexpected output %q", out)
+	}
+	env := make(map[string]string, len(keys))
+	for i, key := range keys {
+		env[key] = lines[i]
+	}
+	return env, nil
+})
+
+// packageGoFiles returns the non-test Go files of each import path,
+// resolved offline from GOROOT or the module cache
+func packageGoFiles(importPaths []string) ([]string, error) {
+	var goFiles []string
+	for _, importPath := range importPaths {
+		dir, err := resolvePackageDir(importPath)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+				goFiles = append(goFiles, filepath.Join(dir, name))
+			}
+		}
+	}
+	return goFiles, nil
+}
+
+// resolvePackageDir finds the directory holding an import path, checking the
+// standard library first and then the highest cached version of the module
+func resolvePackageDir(importPath string) (string, error) {
+	env, err := goEnv()
+	if err != nil {
+		return "", err
+	}
+	importPath = strings.Trim(importPath, "/ ")
+
+	stdDir := filepath.Join(env["GOROOT"], "src", filepath.FromSlash(importPath))
+	if info, err := os.Stat(stdDir); err == nil && info.IsDir() {
+		return stdDir, nil
+	}
+
+	// Try the longest module path first, e.g. github.com/a/b/c then github.com/a/b
+	parts := strings.Split(importPath, "/")
+	for i := len(parts); i > 0; i-- {
+		modulePath := strings.Join(parts[:i], "/")
+		moduleDir, ok := latestCachedModule(env["GOMODCACHE"], modulePath)
+		if !ok {
+			continue
+		}
+		dir := filepath.Join(moduleDir, filepath.FromSlash(strings.Join(parts[i:], "/")))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+
+	return "", fmt.Errorf("package %s not found in GOROOT or the module cache", importPath)
+}
+
+// latestCachedModule returns the module cache directory of the highest
+// downloaded version of modulePath
+func latestCachedModule(modCache, modulePath string) (string, bool) {
+	escaped := escapeModulePath(modulePath)
+	matches, _ := filepath.Glob(filepath.Join(modCache, filepath.FromSlash(escaped)+"@*"))
+
+	best, bestVersion := "", ""
+	for _, match := range matches {
+		version := match[strings.LastIndex(match, "@")+1:]
+		if best == "" || compareVersions(version, bestVersion) > 0 {
+			best, bestVersion = match, version
+		}
+	}
+	return best, best != ""
+}
+
+// escapeModulePath applies the module cache's case encoding, where each
+// upper-case letter becomes '!' followed by its lower-case form
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if unicode.IsUpper(r) {
+			b.WriteByte('!')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// compareVersions orders semantic versions like v1.2.3, treating releases as
+// newer than pre-releases (including pseudo-versions) of the same version
+func compareVersions(a, b string) int {
+	aCore, aPre, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	bCore, bPre, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+	aCore, _, _ = strings.Cut(aCore, "+")
+	bCore, _, _ = strings.Cut(bCore, "+")
+
+	aParts, bParts := strings.Split(aCore, "."), strings.Split(bCore, ".")
+	for i := 0; i < max(len(aParts), len(bParts)); i++ {
+		var aNum, bNum int
+		if i < len(aParts) {
+			aNum, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bNum, _ = strconv.Atoi(bParts[i])
+		}
+		if aNum != bNum {
+			return aNum - bNum
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return comparePrerelease(aPre, bPre)
+}
+
+// comparePrerelease orders pre-release tags by their dot-separated
+// identifiers as semver does: numbers numerically and below words, so rc.2
+// comes before rc.10
+func comparePrerelease(a, b string) int {
+	aIDs, bIDs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < min(len(aIDs), len(bIDs)); i++ {
+		aID, bID := aIDs[i], bIDs[i]
+		aNum, bNum := isNumeric(aID), isNumeric(bID)
+		switch {
+		case aNum && bNum && len(aID) != len(bID):
+			return len(aID) - len(bID) // No leading zeros, so longer is bigger
+		case aNum && !bNum:
+			return -1
+		case !aNum && bNum:
+			return 1
+		}
+		if c := strings.Compare(aID, bID); c != 0 {
+			return c
+		}
+	}
+	return len(aIDs) - len(bIDs)
+}
+
+func isNumeric(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int // Sign of the result
+	}{
+		{"v1.2.3", "v1.2.3", 0},
+		{"v1.10.0", "v1.9.0", 1},
+		{"v1.9.9", "v2.0.0", -1},
+		{"v1.2", "v1.2.0", 0},
+		{"v1.2.3", "v1.2.3-rc.1", 1},
+		{"v1.2.3-rc.1", "v1.2.3", -1},
+		{"v1.2.3-rc.1", "v1.2.3-rc.2", -1},
+		{"v1.2.3-rc.10", "v1.2.3-rc.2", 1},
+		{"v1.2.3-rc.2", "v1.2.3-rc.10", -1},
+		{"v1.2.3-alpha", "v1.2.3-alpha.1", -1},
+		{"v1.2.3-alpha.1", "v1.2.3-alpha.beta", -1}, // Numbers below words
+		{"v1.2.3-beta", "v1.2.3-alpha", 1},
+		{"v1.2.3-1", "v1.2.3-alpha", -1},
+		{"v0.0.0-20240101000000-abcdef123456", "v0.0.0-20231231000000-123456abcdef", 1}, // Pseudo-versions by time
+		{"v0.0.0-20240101000000-abcdef123456", "v0.1.0", -1},
+		{"v2.0.0+incompatible", "v2.0.0", 0},
+		{"v1.21.0", "v1.3.10", 1},
+	}
+	for _, tt := range tests {
+		got := compareVersions(tt.a, tt.b)
+		if sign(got) != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want sign %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n > 0:
+		return 1
+	case n < 0:
+		return -1
+	}
+	return 0
+}
+
+func TestLatestCachedModule(t *testing.T) {
+	modCache := t.TempDir()
+	for _, dir := range []string{
+		"github.com/spf13/viper@v1.9.0",
+		"github.com/spf13/viper@v1.21.0",
+		"github.com/spf13/viper@v1.21.0-rc.1",
+		"github.com/spf13/viper-extra@v9.0.0",
+		"github.com/!burnt!sushi/toml@v1.3.2",
+		"github.com/!burnt!sushi/toml@v1.4.0",
+	} {
+		if err := os.MkdirAll(filepath.Join(modCache, filepath.FromSlash(dir)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		module string
+		want   string // Relative to the module cache, empty for none
+	}{
+		{"github.com/spf13/viper", "github.com/spf13/viper@v1.21.0"},
+		{"github.com/BurntSushi/toml", "github.com/!burnt!sushi/toml@v1.4.0"},
+		{"github.com/spf13/cobra", ""},
+	}
+	for _, tt := range tests {
+		got, ok := latestCachedModule(modCache, tt.module)
+		want := ""
+		if tt.want != "" {
+			want = filepath.Join(modCache, filepath.FromSlash(tt.want))
+		}
+		if got != want || ok != (tt.want != "") {
+			t.Errorf("latestCachedModule(%q) = %q, %v, want %q", tt.module, got, ok, want)
+		}
+	}
+}
@@ -7,91 +7,285 @@ import (
 	"go/token"
 	"math/rand"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 )
 
-func loadRandomFunction(cfg config) (string, string, error) {
-	folderPath := cfg.FolderPath
+// Snippet sources
+const (
+	sourceFolder    = "folder"     // Any function under FolderPath
+	sourceGitRecent = "git-recent" // Only functions recently changed in git under FolderPath
+	sourcePackages  = "packages"   // Standard library or module cache packages by import path
+	sourceEmbedded  = "embedded"   // The curated corpus built into the binary
+)
 
-	// Expand home directory if needed
-	if strings.HasPrefix(folderPath, "~") {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return "", "", err
-		}
-		folderPath = filepath.Join(homeDir, folderPath[1:])
+var sources = []string{sourceFolder, sourceGitRecent, sourcePackages, sourceEmbedded}
+
+// snippet is a function extracted from a source file
+type snippet struct {
+	Text      string
+	File      string
+	Package   string
+	Receiver  string // e.g. *Server, empty for plain functions
+	Name      string
+	StartLine int
+	EndLine   int
+}
+
+// DisplayName returns the function name qualified by its receiver,
+// e.g. (*Server).ServeHTTP
+func (s snippet) DisplayName() string {
+	if s.Receiver == "" {
+		return s.Name
 	}
+	return "(" + s.Receiver + ")." + s.Name
+}
 
-	var goFiles []string
-	err := filepath.Walk(folderPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil // Skip files we can't access
-		}
-		if !info.IsDir() && strings.HasSuffix(path, ".go") {
-			goFiles = append(goFiles, path)
-		}
-		return nil
-	})
+// Kind returns the snippet's kind, functions or methods
+func (s snippet) Kind() string {
+	if s.Receiver == "" {
+		return kindFunctions
+	}
+	return kindMethods
+}
 
+// loadCorpusFunction picks a snippet from a single source
+func loadCorpusFunction(cfg config, rng *rand.Rand) (string, string, error) {
+	if err := checkKinds(cfg.Kinds); err != nil {
+		return "", "", err
+	}
+	goFiles, err := corpusFiles(cfg)
 	if err != nil {
 		return "", "", err
 	}
+	if strings.HasPrefix(goFiles[0], embeddedPrefix) {
+		// The embedded corpus mixes in proverbs as well as functions
+		return loadEmbeddedFunction(cfg, rng)
+	}
 
-	if len(goFiles) == 0 {
-		return "", "", fmt.Errorf("no Go files found in %s", folderPath)
+	folderPath, err := expandHome(cfg.FolderPath)
+	if err != nil {
+		return "", "", err
 	}
 
+	// Restrict to recently changed files and lines if requested
+	var changed changedLines
+	if cfg.Source == sourceGitRecent {
+		changed, err = gitRecentChanges(folderPath, cfg)
+		if err != nil {
+			return "", "", err
+		}
+
+		var recentFiles []string
+		for _, path := range goFiles {
+			if len(changed[path]) > 0 {
+				recentFiles = append(recentFiles, path)
+			}
+		}
+		if len(recentFiles) == 0 {
+			return "", "", fmt.Errorf("no recently changed Go files found in %s", folderPath)
+		}
+		goFiles = recentFiles
+	}
+
+	// Blame is expensive, so each file is only blamed once per selection
+	blameCache := make(map[string][]string)
+
 	// Try to find a suitable function
 	maxAttempts := len(goFiles) * 3
 	for attempt := 0; attempt < maxAttempts; attempt++ {
-		randomFile := goFiles[rand.Intn(len(goFiles))]
+		randomFile := goFiles[rng.Intn(len(goFiles))]
 		functions, err := extractFunctions(randomFile)
 		if err != nil {
 			continue
 		}
 
-		// Filter functions based on line count
+		var authors []string
+		if cfg.AuthorEmail != "" {
+			var ok bool
+			if authors, ok = blameCache[randomFile]; !ok {
+				authors, _ = gitBlameAuthors(randomFile) // Untracked files have no author
+				blameCache[randomFile] = authors
+			}
+		}
+
+		// Filter functions based on kind, line count, recent changes and authorship
 		var validFunctions []string
 		for _, fn := range functions {
-			lines := countLines(fn)
-			if lines >= cfg.MinLines && lines <= cfg.MaxLines {
-				validFunctions = append(validFunctions, fn)
+			if !wantKind(cfg.Kinds, fn.Kind()) {
+				continue
+			}
+			lines := countLines(fn.Text)
+			if lines < cfg.MinLines || lines > cfg.MaxLines {
+				continue
 			}
+			if changed != nil && !changed.overlaps(randomFile, fn.StartLine, fn.EndLine) {
+				continue
+			}
+			if cfg.AuthorEmail != "" && !mostlyAuthoredBy(authors, fn.StartLine, fn.EndLine, cfg.AuthorEmail) {
+				continue
+			}
+			validFunctions = append(validFunctions, fn.Text)
 		}
 
 		if len(validFunctions) > 0 {
-			return validFunctions[rand.Intn(len(validFunctions))], randomFile, nil
+			return validFunctions[rng.Intn(len(validFunctions))], randomFile, nil
 		}
 	}
 
+	if cfg.AuthorEmail != "" {
+		return "", "", fmt.Errorf("no functions between %d and %d lines by %s found after %d attempts", cfg.MinLines, cfg.MaxLines, cfg.AuthorEmail, maxAttempts)
+	}
 	return "", "", fmt.Errorf("no functions between %d and %d lines found after %d attempts", cfg.MinLines, cfg.MaxLines, maxAttempts)
 }
 
-func extractFunctions(filePath string) ([]string, error) {
+// findFunction returns the named function from a file, matching either the
+// bare name or the receiver-qualified name. An empty name picks at random.
+func findFunction(filePath, name string, rng *rand.Rand) (snippet, error) {
+	functions, err := extractFunctions(filePath)
+	if err != nil {
+		return snippet{}, err
+	}
+	if len(functions) == 0 {
+		return snippet{}, fmt.Errorf("no functions found in %s", filePath)
+	}
+	if name == "" {
+		return functions[rng.Intn(len(functions))], nil
+	}
+
+	for _, fn := range functions {
+		if fn.DisplayName() == name || fn.Name == name {
+			return fn, nil
+		}
+	}
+	return snippet{}, fmt.Errorf("function %s not found in %s", name, filePath)
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, path[1:]), nil
+}
+
+// corpusFiles lists the Go files of the configured source that aren't
+// ignored. A folder without any Go files falls back to the embedded corpus.
+func corpusFiles(cfg config) ([]string, error) {
+	goFiles, err := sourceFiles(cfg)
+	if err != nil || len(cfg.Ignore) == 0 {
+		return goFiles, err
+	}
+
+	folderPath, _ := expandHome(cfg.FolderPath)
+	var kept []string
+	for _, file := range goFiles {
+		rel := strings.TrimPrefix(file, embeddedPrefix)
+		if cfg.Source == sourcePackages {
+			rel = path.Join(moduleRelativeDir(filepath.Dir(file)), filepath.Base(file))
+		} else if r, err := filepath.Rel(folderPath, file); err == nil && !strings.HasPrefix(file, embeddedPrefix) {
+			rel = r
+		}
+		if !ignored(rel, cfg.Ignore) {
+			kept = append(kept, file)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("every Go file is ignored by %s", strings.Join(cfg.Ignore, ", "))
+	}
+	return kept, nil
+}
+
+// sourceFiles lists every Go file of the configured source
+func sourceFiles(cfg config) ([]string, error) {
+	switch cfg.Source {
+	case sourcePackages:
+		if len(cfg.Packages) == 0 {
+			return nil, fmt.Errorf("no packages configured")
+		}
+		goFiles, err := packageGoFiles(cfg.Packages)
+		if err != nil {
+			return nil, err
+		}
+		if len(goFiles) == 0 {
+			return nil, fmt.Errorf("no Go files found in %s", strings.Join(cfg.Packages, ", "))
+		}
+		return goFiles, nil
+	case sourceEmbedded:
+		return embeddedGoFiles()
+	}
+
+	folderPath, err := expandHome(cfg.FolderPath)
+	if err != nil {
+		return nil, err
+	}
+	goFiles, err := folderGoFiles(folderPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(goFiles) == 0 {
+		// Fall back to the built-in corpus so there's always something to type
+		if cfg.Source == sourceFolder {
+			return embeddedGoFiles()
+		}
+		return nil, fmt.Errorf("no Go files found in %s", folderPath)
+	}
+	return goFiles, nil
+}
+
+// folderGoFiles returns every Go file under folderPath
+func folderGoFiles(folderPath string) ([]string, error) {
+	var goFiles []string
+	err := filepath.Walk(folderPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // Skip files we can't access
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ".go") {
+			goFiles = append(goFiles, path)
+		}
+		return nil
+	})
+	return goFiles, err
+}
+
+func extractFunctions(filePath string) ([]snippet, error) {
+	content, err := readSource(filePath)
+	if err != nil {
+		return nil, err
+	}
+
 	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
+	node, err := parser.ParseFile(fset, filePath, content, parser.ParseComments)
 	if err != nil {
 		return nil, err
 	}
 
-	var functions []string
+	var functions []snippet
+	lines := strings.Split(string(content), "\n")
 
 	ast.Inspect(node, func(n ast.Node) bool {
 		if fn, ok := n.(*ast.FuncDecl); ok {
 			start := fset.Position(fn.Pos())
 			end := fset.Position(fn.End())
 
-			// Read the source file
-			content, err := os.ReadFile(filePath)
-			if err != nil {
-				return true
-			}
-
-			lines := strings.Split(string(content), "\n")
 			if start.Line <= len(lines) && end.Line <= len(lines) {
-				funcText := strings.Join(lines[start.Line-1:end.Line], "\n")
-				functions = append(functions, funcText)
+				s := snippet{
+					Text:      strings.Join(lines[start.Line-1:end.Line], "\n"),
+					File:      filePath,
+					Package:   node.Name.Name,
+					Name:      fn.Name.Name,
+					StartLine: start.Line,
+					EndLine:   end.Line,
+				}
+				if fn.Recv != nil && len(fn.Recv.List) > 0 {
+					s.Receiver = receiverName(fn.Recv.List[0].Type)
+				}
+				functions = append(functions, s)
 			}
 		}
 		return true
@@ -99,3 +293,26 @@ func extractFunctions(filePath string) ([]string, error) {
 
 	return functions, nil
 }
+
+// readSource reads a file from disk or from the embedded corpus
+func readSource(filePath string) ([]byte, error) {
+	if name, ok := strings.CutPrefix(filePath, embeddedPrefix); ok {
+		return embeddedCorpus.ReadFile(name)
+	}
+	return os.ReadFile(filePath)
+}
+
+// receiverName renders a method receiver type without type parameter names
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + receiverName(t.X)
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
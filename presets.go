@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/viper"
+)
+
+// builtinPresets are available without any configuration. Presets in the
+// config file with the same name replace them.
+var builtinPresets = map[string]map[string]any{
+	"warmup": {
+		"min_lines":      5,
+		"max_lines":      15,
+		"max_time_limit": 30,
+	},
+	"marathon": {
+		"min_lines":      50,
+		"max_lines":      200,
+		"max_time_limit": 0,
+	},
+	"stdlib-only": {
+		"source":   sourcePackages,
+		"packages": []string{"bytes", "errors", "fmt", "io", "net/http", "sort", "strconv", "strings", "sync"},
+		"corpora":  []corpus{},
+	},
+}
+
+// presetName normalises a preset name. Viper lowercases the keys of the
+// config file's presets map, so names are matched case-insensitively.
+func presetName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// loadPresets returns every preset by name as overrides keyed like the
+// config file
+func loadPresets() map[string]map[string]any {
+	presets := maps.Clone(builtinPresets)
+	for name := range viper.GetStringMap("presets") {
+		overrides := viper.GetStringMap("presets." + name)
+		dropRunOnlyKeys(overrides)
+		presets[name] = overrides
+	}
+	return presets
+}
+
+// presetNames lists the presets in switching order
+func presetNames() []string {
+	return slices.Sorted(maps.Keys(loadPresets()))
+}
+
+// presetChanged reports whether overrides differ from the saved preset
+func presetChanged(name string, overrides map[string]any) bool {
+	saved := loadPresets()[name]
+	for key, value := range overrides {
+		// Compare printed values, as the file's lists decode as []any
+		if fmt.Sprint(saved[key]) != fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextPreset switches to the preset after the active one, cycling through
+// no preset, and loads a fresh snippet with its settings
+func (m *model) nextPreset() tea.Cmd {
+	names := append([]string{""}, presetNames()...)
+	next := names[(slices.Index(names, m.config.Preset)+1)%len(names)]
+
+	viper.Set("preset", next)
+	m.config, m.configErr = loadConfig()
+	m.ownSettings = nil
+	m.pendingRules = nil
+	m.watcher.watch(m.config)
+	if err := saveConfig(m.config); err != nil {
+		m.err = err
+		return nil
+	}
+
+	var cmd tea.Cmd
+	if m.targetText != "" {
+		cmd = m.loadNextFunction()
+	}
+	if next == "" {
+		m.status = "No preset, using your settings"
+	} else {
+		m.status = "Preset: " + next
+	}
+	return cmd
+}
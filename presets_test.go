@@ -0,0 +1,30 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestPresetNamesIgnoreCase(t *testing.T) {
+	path := testConfigDir(t)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("presets:\n  Quick:\n    max_lines: 12\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"Quick", "quick", " QUICK "} {
+		viper.Set("preset", name)
+		cfg, err := loadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Preset != "quick" || cfg.MaxLines != 12 {
+			t.Errorf("preset %q gave preset %q with max_lines %d, want quick with 12", name, cfg.Preset, cfg.MaxLines)
+		}
+	}
+}
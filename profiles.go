@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/viper"
+)
+
+// profileName is the active profile, empty for the shared default profile.
+// A name that isn't a plain directory name is never used to build paths;
+// the command line rejects it before anything runs.
+func profileName() string {
+	name := strings.TrimSpace(viper.GetString("profile"))
+	if name != "" && checkProfileName(name) != nil {
+		return ""
+	}
+	return name
+}
+
+// profileConfigDir holds the active profile's config file
+func profileConfigDir() string {
+	return profileDir(configDir())
+}
+
+// profileStateDir holds the active profile's history
+func profileStateDir() string {
+	return profileDir(stateDir())
+}
+
+// profileDir returns the active profile's directory under base, which is
+// base itself for the default profile
+func profileDir(base string) string {
+	if name := profileName(); name != "" {
+		return filepath.Join(base, "profiles", name)
+	}
+	return base
+}
+
+// checkProfileName rejects names that can't be used as a directory
+func checkProfileName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	return nil
+}
+
+// profileNames lists the profiles that have settings or history on this
+// machine
+func profileNames() []string {
+	var names []string
+	for _, base := range []string{configDir(), stateDir(), legacyDataDir()} {
+		entries, _ := os.ReadDir(filepath.Join(base, "profiles"))
+		for _, entry := range entries {
+			if entry.IsDir() && !slices.Contains(names, entry.Name()) {
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// nextProfile switches to the next profile, cycling through the default
+// profile, and reloads its settings and personal bests
+func (m *model) nextProfile() tea.Cmd {
+	names := append([]string{""}, profileNames()...)
+	next := names[(slices.Index(names, profileName())+1)%len(names)]
+
+	viper.Set("profile", next)
+	m.config, m.configErr = loadConfig()
+	m.ownSettings = nil
+	m.pendingRules = nil
+	m.watcher.watch(m.config)
+	m.bests = loadPersonalBests()
+
+	var cmd tea.Cmd
+	if m.targetText != "" {
+		cmd = m.loadNextFunction()
+	}
+	if next == "" {
+		m.status = "Profile: default"
+	} else {
+		m.status = "Profile: " + next
+	}
+	return cmd
+}
+
+// loadPersonalBests returns the best completed WPM per category from the
+// active profile's history
+func loadPersonalBests() map[string]float64 {
+	bests := make(map[string]float64)
+	results, _ := loadHistory() // No history means no bests yet
+	for _, r := range results {
+		if r.Outcome == outcomeCompleted {
+			label := r.categoryLabel()
+			bests[label] = max(bests[label], r.WPM)
+		}
+	}
+	return bests
+}
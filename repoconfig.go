@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/viper"
+)
+
+// repoConfigName is the settings file a repository can ship at its root
+const repoConfigName = ".typing_vibes.yaml"
+
+// findRepoConfig walks up from folderPath to the repository root looking for
+// a repository config file
+func findRepoConfig(folderPath string) string {
+	dir, err := expandHome(folderPath)
+	if err != nil || dir == "" {
+		return ""
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		path := filepath.Join(dir, repoConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		// Don't look above the repository root
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// repoConfigKeys are the corpus settings a repository config may set. Anything
+// else, like where settings and history are saved, stays the user's choice.
+var repoConfigKeys = []string{"ignore", "kinds", "min_lines", "max_lines", "presets"}
+
+// mergeRepoConfig layers a repository config file over the settings read so
+// far and returns the keys it sets
+func mergeRepoConfig(path string) ([]string, error) {
+	settings, _, err := readConfigFile(path)
+	if settings == nil {
+		return nil, err
+	}
+	for key := range settings {
+		if !slices.Contains(repoConfigKeys, key) {
+			delete(settings, key)
+		}
+	}
+	viper.MergeConfigMap(settings)
+
+	var keys []string
+	for key, value := range settings {
+		keys = append(keys, key)
+		if key != "presets" {
+			continue
+		}
+		if presets, ok := value.(map[string]any); ok {
+			for name := range presets {
+				keys = append(keys, "presets."+name)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return keys, err
+}
+
+// repoControls reports whether a setting comes from the repository config,
+// either directly or through a preset it defines, in which case it can't be
+// changed from the app
+func (cfg config) repoControls(key string) bool {
+	if slices.Contains(cfg.RepoKeys, key) {
+		return true
+	}
+	if cfg.Preset == "" || !slices.Contains(cfg.RepoKeys, "presets."+cfg.Preset) {
+		return false
+	}
+	_, ok := loadPresets()[cfg.Preset][key]
+	return ok
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestMergeRepoConfigKeepsCorpusKeys(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), repoConfigName)
+	yaml := "profile: ../../escaped\nconfig: /tmp/elsewhere.yaml\ncwd_config: true\nfolder_path: /etc\n" +
+		"min_lines: 8\nkinds: [methods]\npresets:\n  onboarding:\n    max_lines: 25\n    profile: ../x\n"
+	if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := mergeRepoConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"kinds", "min_lines", "presets", "presets.onboarding"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+	for _, key := range []string{"profile", "config", "cwd_config", "folder_path"} {
+		if viper.IsSet(key) {
+			t.Errorf("%s = %v, want it ignored", key, viper.Get(key))
+		}
+	}
+	if _, ok := loadPresets()["onboarding"]["profile"]; ok {
+		t.Error("preset kept profile")
+	}
+	if got := viper.GetInt("min_lines"); got != 8 {
+		t.Errorf("min_lines = %d, want 8", got)
+	}
+}
+
+func TestProfileNameRejectsPaths(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	for name, want := range map[string]string{"work": "work", " work ": "work", "../../escaped": "", "a/b": "", "..": ""} {
+		viper.Set("profile", name)
+		if got := profileName(); got != want {
+			t.Errorf("profileName() with %q = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// selectionSeed returns the seed for snippet selection and, for the daily
+// challenge, the date it belongs to. The daily seed derives from the date and
+// the corpus, so everyone on the same repository revision gets the same
+// functions that day.
+func selectionSeed(cfg config) (int64, string) {
+	if viper.GetBool("daily") {
+		// UTC, so players in different time zones share the same daily challenge
+		day := time.Now().UTC().Format(time.DateOnly)
+		h := fnv.New64a()
+		h.Write([]byte(day + "\x00" + corpusIdentity(cfg)))
+		return int64(h.Sum64()), day
+	}
+	if seed := viper.GetInt64("seed"); seed != 0 {
+		return seed, ""
+	}
+	return time.Now().UnixNano(), ""
+}
+
+// newSelectionRand returns a dedicated random source for snippet selection
+func newSelectionRand(seed int64) *rand.Rand {
+	return rand.New(rand.NewSource(seed))
+}
+
+// corpusIdentity describes the corpus independently of where it's checked out:
+// the git revision and subdirectory for folders inside a repository, the
+// toolchain and resolved module versions for packages
+func corpusIdentity(cfg config) string {
+	if len(cfg.Corpora) > 0 {
+		var identities []string
+		for _, c := range cfg.Corpora {
+			identities = append(identities, fmt.Sprintf("%d*%s", c.weight(), corpusIdentity(c.config(cfg))))
+		}
+		return strings.Join(identities, ";")
+	}
+
+	switch cfg.Source {
+	case sourceEmbedded:
+		return sourceEmbedded
+	case sourcePackages:
+		// The toolchain version pins the standard library
+		dirs := []string{toolchainVersion()}
+		for _, importPath := range cfg.Packages {
+			if dir, err := resolvePackageDir(importPath); err == nil {
+				dirs = append(dirs, moduleRelativeDir(dir))
+			}
+		}
+		return sourcePackages + ":" + strings.Join(dirs, ",")
+	}
+
+	folderPath, err := expandHome(cfg.FolderPath)
+	if err != nil {
+		return cfg.Source
+	}
+	if rev, err := runGit(folderPath, "rev-parse", "HEAD"); err == nil {
+		prefix, _ := runGit(folderPath, "rev-parse", "--show-prefix")
+		return cfg.Source + ":" + rev + ":" + prefix
+	}
+	return cfg.Source + ":" + filepath.Base(folderPath)
+}
+
+// moduleRelativeDir strips the GOROOT or module cache prefix from a package
+// directory, leaving e.g. "github.com/spf13/viper@v1.21.0"
+func moduleRelativeDir(dir string) string {
+	env, err := goEnv()
+	if err != nil {
+		return dir
+	}
+	for _, root := range []string{filepath.Join(env["GOROOT"], "src"), env["GOMODCACHE"]} {
+		if rel, err := filepath.Rel(root, dir); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return dir
+}
+
+func toolchainVersion() string {
+	env, err := goEnv()
+	if err != nil {
+		return ""
+	}
+	return env["GOVERSION"]
+}
@@ -0,0 +1,556 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// settingKind is how a setting is edited in the settings form
+type settingKind int
+
+const (
+	settingText   settingKind = iota // Free text, lists are comma separated
+	settingPath                      // A directory
+	settingToggle                    // On or off
+	settingChoice                    // One of a fixed set of values
+	settingSlider                    // A number within a range
+)
+
+// setting describes one field of the settings form, bound to a config struct
+// field and the viper key it's saved under
+type setting struct {
+	section     string
+	label       string
+	key         string
+	kind        settingKind
+	choices     []string // settingChoice values
+	min, max    int      // settingSlider range
+	step        int      // settingSlider change per key press
+	placeholder string   // settingText and settingPath hint
+	field       func(*config) any
+
+	// settingChoice values that can change, like theme names, used instead of choices
+	choicesFunc func() []string
+}
+
+// settingsSchema lists every setting in form order. Fields are *string,
+// *[]string, *int or *bool pointers into the config.
+var settingsSchema = []setting{
+	{section: "Corpus", label: "Source", key: "source", kind: settingChoice, choices: sources,
+		field: func(c *config) any { return &c.Source }},
+	{section: "Corpus", label: "Folder Path", key: "folder_path", kind: settingPath, placeholder: "~/code",
+		field: func(c *config) any { return &c.FolderPath }},
+	{section: "Corpus", label: "Packages (import paths for the packages source, comma separated)", key: "packages", kind: settingText,
+		placeholder: "net/http, github.com/spf13/viper", field: func(c *config) any { return &c.Packages }},
+	{section: "Corpus", label: "Minimum Lines", key: "min_lines", kind: settingSlider, min: 1, max: 100, step: 1,
+		field: func(c *config) any { return &c.MinLines }},
+	{section: "Corpus", label: "Maximum Lines", key: "max_lines", kind: settingSlider, min: 1, max: 500, step: 5,
+		field: func(c *config) any { return &c.MaxLines }},
+	{section: "Corpus", label: "Author Email (git blame, empty = anyone)", key: "author_email", kind: settingText,
+		placeholder: "you@example.com", field: func(c *config) any { return &c.AuthorEmail }},
+	{section: "Corpus", label: "Git Recent Days (0 = all history)", key: "git_recent_days", kind: settingSlider, min: 0, max: 365, step: 7,
+		field: func(c *config) any { return &c.GitRecentDays }},
+	{section: "Corpus", label: "Git Recent Commits (0 = use days, otherwise overrides them)", key: "git_recent_commits", kind: settingSlider, min: 0, max: 200, step: 5,
+		field: func(c *config) any { return &c.GitRecentCommits }},
+	{section: "Corpus", label: "Git Base Branch (empty = none)", key: "git_base_branch", kind: settingText, placeholder: "main",
+		field: func(c *config) any { return &c.GitBaseBranch }},
+
+	{section: "Test", label: "Mode", key: "mode", kind: settingChoice, choices: modes,
+		field: func(c *config) any { return &c.Mode }},
+	{section: "Test", label: "Max Time Limit (seconds, 0 = no limit)", key: "max_time_limit", kind: settingSlider, min: 0, max: 600, step: 5,
+		field: func(c *config) any { return &c.MaxTimeLimit }},
+	{section: "Test", label: "Blind Mode", key: "blind_mode", kind: settingToggle,
+		field: func(c *config) any { return &c.BlindMode }},
+	{section: "Test", label: "Memory Mode", key: "memory_mode", kind: settingToggle,
+		field: func(c *config) any { return &c.MemoryMode }},
+	{section: "Test", label: "Study Time (seconds, 0 = until a key is pressed)", key: "study_seconds", kind: settingSlider, min: 0, max: 120, step: 5,
+		field: func(c *config) any { return &c.StudySeconds }},
+
+	{section: "Display", label: "Theme", key: "theme", kind: settingChoice, choicesFunc: themeNames,
+		field: func(c *config) any { return &c.Theme }},
+	{section: "Display", label: "Lookahead Lines (-1 = all, 0 = current line only)", key: "lookahead_lines", kind: settingSlider, min: -1, max: 50, step: 1,
+		field: func(c *config) any { return &c.LookaheadLines }},
+
+	{section: "Keys", label: "Lock Completed Tokens (backspace stops at correctly typed tokens)", key: "lock_completed_tokens", kind: settingToggle,
+		field: func(c *config) any { return &c.LockCompletedTokens }},
+}
+
+// value returns the setting's current value in cfg
+func (s setting) value(cfg *config) any {
+	switch field := s.field(cfg).(type) {
+	case *string:
+		return *field
+	case *[]string:
+		return *field
+	case *int:
+		return *field
+	case *bool:
+		return *field
+	}
+	panic("unsupported setting field for " + s.key)
+}
+
+// text formats a text or path setting for its input
+func (s setting) text(cfg *config) string {
+	if list, ok := s.field(cfg).(*[]string); ok {
+		return strings.Join(*list, ", ")
+	}
+	return fmt.Sprint(s.value(cfg))
+}
+
+// setText stores a text or path setting from its input
+func (s setting) setText(cfg *config, text string) {
+	switch field := s.field(cfg).(type) {
+	case *string:
+		*field = strings.TrimSpace(text)
+	case *[]string:
+		*field = splitList(text)
+	}
+}
+
+// options returns a choice setting's values
+func (s setting) options() []string {
+	if s.choicesFunc != nil {
+		return s.choicesFunc()
+	}
+	return s.choices
+}
+
+// copyValue sets the setting in dst to its value in src
+func (s setting) copyValue(dst, src *config) {
+	switch field := s.field(dst).(type) {
+	case *string:
+		*field = *s.field(src).(*string)
+	case *[]string:
+		*field = slices.Clone(*s.field(src).(*[]string))
+	case *int:
+		*field = *s.field(src).(*int)
+	case *bool:
+		*field = *s.field(src).(*bool)
+	}
+}
+
+// adjust moves a toggle, choice or slider by delta steps
+func (s setting) adjust(cfg *config, delta int) {
+	switch s.kind {
+	case settingToggle:
+		field := s.field(cfg).(*bool)
+		*field = !*field
+	case settingChoice:
+		field := s.field(cfg).(*string)
+		choices := s.options()
+		i := slices.Index(choices, *field)
+		*field = choices[(i+delta+len(choices))%len(choices)]
+	case settingSlider:
+		field := s.field(cfg).(*int)
+		*field = min(max(*field+delta*s.step, s.min), s.max)
+	}
+}
+
+// typeNumber edits a slider's value with typed digits, Backspace to drop the
+// last digit and - to flip the sign. A digit that would go out of range
+// starts a new number. It returns false for other keys.
+func (s setting) typeNumber(cfg *config, msg tea.KeyMsg) bool {
+	field := s.field(cfg).(*int)
+	switch {
+	case msg.Type == tea.KeyBackspace:
+		*field /= 10
+	case msg.Type == tea.KeyRunes && string(msg.Runes) == "-" && s.min < 0:
+		*field = -*field
+	case msg.Type == tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if r < '0' || r > '9' {
+				return false
+			}
+			d := int(r - '0')
+			n := *field*10 + d
+			if *field < 0 {
+				n = *field*10 - d
+			}
+			if n < s.min || n > s.max {
+				n = d
+			}
+			*field = n
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// openSettings shows the settings form with a draft copy of the config
+func (m *model) openSettings() {
+	m.showingConfig = true
+	m.showingPicker = false
+	m.draft = m.savedConfig()
+	m.configErrors = nil
+	m.settingsScroll = 0
+	m.settingInputs = make(map[string]textinput.Model)
+	for _, s := range settingsSchema {
+		if s.kind != settingText && s.kind != settingPath {
+			continue
+		}
+		ti := textinput.New()
+		ti.Placeholder = s.placeholder
+		ti.SetValue(s.text(&m.draft))
+		ti.Width = 50
+		m.settingInputs[s.key] = ti
+	}
+	m.focusSetting(0)
+}
+
+// focusSetting moves the settings form's focus to field i
+func (m *model) focusSetting(i int) {
+	m.focusIndex = i
+	for j, s := range settingsSchema {
+		ti, ok := m.settingInputs[s.key]
+		if !ok {
+			continue
+		}
+		if j == i {
+			ti.Focus()
+		} else {
+			ti.Blur()
+		}
+		m.settingInputs[s.key] = ti
+	}
+	m.scrollSettings()
+}
+
+// updateSettings handles keys while the settings form is open
+func (m model) updateSettings(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.showingPicker {
+		return m.updateFolderPicker(msg)
+	}
+	focused := settingsSchema[m.focusIndex]
+
+	// Settings from the repository config can only be changed in that file
+	if m.draft.repoControls(focused.key) {
+		switch msg.Type {
+		case tea.KeyEsc, tea.KeyCtrlS, tea.KeyEnter, tea.KeyTab, tea.KeyDown, tea.KeyShiftTab, tea.KeyUp:
+		default:
+			return m, nil
+		}
+	}
+
+	if focused.kind == settingPath && !m.draft.repoControls(focused.key) {
+		switch msg.Type {
+		case tea.KeyCtrlO:
+			cmd := m.openFolderPicker()
+			return m, cmd
+		case tea.KeyTab:
+			// Complete the path, and only move on once there's nothing to add
+			ti := m.settingInputs[focused.key]
+			if completed, ok := completePath(ti.Value()); ok {
+				ti.SetValue(completed)
+				ti.CursorEnd()
+				m.settingInputs[focused.key] = ti
+				delete(m.configErrors, focused.key)
+				return m, nil
+			}
+		}
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlS:
+		// Cancel settings without saving, undoing any theme preview
+		useTheme(m.config.Theme)
+		m.showingConfig = false
+		return m, nil
+
+	case tea.KeyEnter:
+		return m.saveSettings()
+
+	case tea.KeyTab, tea.KeyDown:
+		m.focusSetting((m.focusIndex + 1) % len(settingsSchema))
+		return m, nil
+
+	case tea.KeyShiftTab, tea.KeyUp:
+		m.focusSetting((m.focusIndex + len(settingsSchema) - 1) % len(settingsSchema))
+		return m, nil
+	}
+
+	ti, isInput := m.settingInputs[focused.key]
+	if !isInput {
+		switch {
+		case focused.kind == settingSlider && focused.typeNumber(&m.draft, msg):
+		case msg.Type == tea.KeyLeft:
+			focused.adjust(&m.draft, -1)
+		case msg.Type == tea.KeyRight, msg.Type == tea.KeySpace:
+			focused.adjust(&m.draft, 1)
+		default:
+			return m, nil
+		}
+		delete(m.configErrors, focused.key)
+		// Preview themes as they're chosen
+		if focused.key == "theme" {
+			if err := useTheme(m.draft.Theme); err != nil {
+				if m.configErrors == nil {
+					m.configErrors = make(map[string]string)
+				}
+				m.configErrors[focused.key] = err.Error()
+			}
+		}
+		return m, nil
+	}
+
+	// Editing a field clears its error until the next save attempt
+	delete(m.configErrors, focused.key)
+	var cmd tea.Cmd
+	m.settingInputs[focused.key], cmd = ti.Update(msg)
+	return m, cmd
+}
+
+// saveSettings validates the draft and saves it, or keeps the form open on
+// the first invalid field
+func (m model) saveSettings() (tea.Model, tea.Cmd) {
+	cfg := m.draft
+	for key, ti := range m.settingInputs {
+		settingsSchema[settingIndex(key)].setText(&cfg, ti.Value())
+	}
+
+	m.configErrors = validateSettings(cfg)
+	if len(m.configErrors) > 0 {
+		for i, s := range settingsSchema {
+			if _, ok := m.configErrors[s.key]; ok {
+				m.focusSetting(i)
+				break
+			}
+		}
+		return m, nil
+	}
+
+	m.config = cfg
+	m.ownSettings = nil // Saved settings replace a challenge's and any reloaded during the round
+	m.pendingRules = nil
+	if err := saveConfig(m.config); err != nil {
+		m.err = err
+	}
+	m.watcher.watch(m.config)
+	m.showingConfig = false
+
+	// Load new function with new settings
+	var cmd tea.Cmd
+	if m.targetText != "" {
+		cmd = m.loadNextFunction()
+	}
+	return m, cmd
+}
+
+func settingIndex(key string) int {
+	return slices.IndexFunc(settingsSchema, func(s setting) bool { return s.key == key })
+}
+
+// validateSettings returns an error message per viper key for every invalid
+// setting in cfg
+func validateSettings(cfg config) map[string]string {
+	errs := make(map[string]string)
+	for _, s := range settingsSchema {
+		switch s.kind {
+		case settingSlider:
+			if n := s.value(&cfg).(int); n < s.min || n > s.max {
+				errs[s.key] = fmt.Sprintf("must be between %d and %d", s.min, s.max)
+			}
+		case settingChoice:
+			if !slices.Contains(s.options(), s.value(&cfg).(string)) {
+				errs[s.key] = "must be one of " + strings.Join(s.options(), ", ")
+			}
+		}
+	}
+
+	if errs["min_lines"] == "" && errs["max_lines"] == "" && cfg.MinLines > cfg.MaxLines {
+		errs["max_lines"] = fmt.Sprintf("must be at least the minimum (%d)", cfg.MinLines)
+	}
+	// Configured corpora replace the source settings
+	source := cfg.Source
+	if len(cfg.Corpora) > 0 {
+		source = ""
+	}
+	switch source {
+	case sourceFolder, sourceGitRecent:
+		if err := checkGoFolder(cfg.FolderPath); err != nil {
+			errs["folder_path"] = err.Error()
+		}
+	case sourcePackages:
+		if len(cfg.Packages) == 0 {
+			errs["packages"] = "list at least one import path for the packages source"
+		}
+	}
+	if cfg.AuthorEmail != "" && !strings.Contains(cfg.AuthorEmail, "@") {
+		errs["author_email"] = "must be an email address"
+	}
+	return errs
+}
+
+// errFoundGoFile stops the walk in checkGoFolder at the first Go file
+var errFoundGoFile = errors.New("found a Go file")
+
+// checkGoFolder reports whether path is a directory with Go files in it
+func checkGoFolder(path string) error {
+	if path == "" {
+		return fmt.Errorf("required")
+	}
+	dir, err := expandHome(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("does not exist")
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("is not a directory")
+	}
+
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasSuffix(path, ".go") {
+			return errFoundGoFile
+		}
+		return nil // Skip files we can't access
+	})
+	if err == errFoundGoFile {
+		return nil
+	}
+	return fmt.Errorf("contains no Go files")
+}
+
+// settingsChrome is the number of lines around the scrolling part of the
+// settings view: title, help and scroll hints
+const settingsChrome = 7
+
+// scrollSettings keeps the focused field inside the visible part of the form
+func (m *model) scrollSettings() {
+	lines, focusStart, focusEnd := m.settingsLines()
+	visible := max(m.height-settingsChrome, 3)
+	if focusEnd > m.settingsScroll+visible {
+		m.settingsScroll = focusEnd - visible
+	}
+	if focusStart < m.settingsScroll {
+		m.settingsScroll = focusStart
+	}
+	m.settingsScroll = max(0, min(m.settingsScroll, len(lines)-visible))
+}
+
+func (m model) renderConfigView() string {
+	var b strings.Builder
+
+	title := "⚙️  Settings"
+	if m.draft.Preset != "" {
+		title += " · preset " + m.draft.Preset + " (its settings are saved to the preset)"
+	}
+	b.WriteString(titleStyle.Render(title))
+	b.WriteString("\n\n")
+
+	lines, _, _ := m.settingsLines()
+	visible := max(m.height-settingsChrome, 3)
+	start := min(m.settingsScroll, max(len(lines)-visible, 0))
+	end := min(start+visible, len(lines))
+
+	if start > 0 {
+		b.WriteString(helpStyle.UnsetMarginTop().Render(fmt.Sprintf("↑ %d more lines", start)))
+	}
+	b.WriteString("\n")
+	b.WriteString(strings.Join(lines[start:end], "\n"))
+	b.WriteString("\n")
+	if end < len(lines) {
+		b.WriteString(helpStyle.UnsetMarginTop().Render(fmt.Sprintf("↓ %d more lines", len(lines)-end)))
+	}
+	b.WriteString("\n")
+
+	help := "Tab/↑/↓ to navigate • ←/→/Space to change • Enter to save • Ctrl+S or Esc to cancel"
+	switch settingsSchema[m.focusIndex].kind {
+	case settingSlider:
+		help = "Tab/↑/↓ to navigate • ←/→/Space or type a number to change • Enter to save • Ctrl+S or Esc to cancel"
+	case settingPath:
+		help = "Tab to complete • Ctrl+O to browse folders • ↑/↓ to navigate • Enter to save • Ctrl+S or Esc to cancel"
+	}
+	b.WriteString(helpStyle.Render(help))
+
+	return b.String()
+}
+
+// settingsLines renders the whole form, returning the line range of the
+// focused field so the view can scroll to it
+func (m model) settingsLines() (lines []string, focusStart, focusEnd int) {
+	section := ""
+	for i, s := range settingsSchema {
+		if s.section != section {
+			section = s.section
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, titleStyle.UnsetMarginBottom().Render("── "+section+" ──"), "")
+		}
+
+		focused := i == m.focusIndex
+		if focused {
+			focusStart = len(lines)
+		}
+		marker := "  "
+		if focused {
+			marker = "▸ "
+		}
+		lines = append(lines, marker+formLabelStyle.Render(s.label+":"))
+		lines = append(lines, "  "+m.renderSetting(s, focused))
+		if s.key == "theme" {
+			lines = append(lines, "  "+labelStyle.Render("Add your own in "+contractHome(themesDir())))
+		}
+		if m.draft.repoControls(s.key) {
+			lines = append(lines, "  "+labelStyle.Render("🔒 set by "+contractHome(m.draft.RepoConfig)))
+		}
+		if msg, ok := m.configErrors[s.key]; ok {
+			lines = append(lines, "  "+errorStyle.Render("✗ "+msg))
+		}
+		if focused {
+			focusEnd = len(lines)
+		}
+		lines = append(lines, "")
+	}
+	return lines, focusStart, focusEnd
+}
+
+// renderSetting draws a field's editor for its kind
+func (m model) renderSetting(s setting, focused bool) string {
+	selected := labelStyle
+	if focused {
+		selected = valueStyle
+	}
+
+	switch s.kind {
+	case settingToggle:
+		if s.value(&m.draft).(bool) {
+			return selected.Render("[x] on")
+		}
+		return selected.Render("[ ] off")
+
+	case settingChoice:
+		current := s.value(&m.draft).(string)
+		var options []string
+		for _, choice := range s.options() {
+			if choice == current {
+				options = append(options, selected.Render("‹ "+choice+" ›"))
+			} else {
+				options = append(options, labelStyle.Render("  "+choice+"  "))
+			}
+		}
+		return strings.Join(options, " ")
+
+	case settingSlider:
+		const width = 20
+		n := s.value(&m.draft).(int)
+		filled := (min(max(n, s.min), s.max) - s.min) * width / (s.max - s.min)
+		bar := strings.Repeat("━", filled) + "●" + strings.Repeat("─", width-filled)
+		return selected.Render(bar + " " + strconv.Itoa(n))
+	}
+
+	return m.settingInputs[s.key].View()
+}
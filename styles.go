@@ -2,52 +2,108 @@ package main
 
 import "github.com/charmbracelet/lipgloss"
 
+// Styles are set from the active theme by applyTheme
 var (
+	titleStyle      lipgloss.Style
+	correctStyle    lipgloss.Style
+	correctedStyle  lipgloss.Style // Orange/yellow for corrected chars
+	incorrectStyle  lipgloss.Style
+	cursorStyle     lipgloss.Style
+	textStyle       lipgloss.Style // Untyped code
+	whitespaceStyle lipgloss.Style // Leading indentation
+	statsStyle      lipgloss.Style
+	warningStyle    lipgloss.Style
+	helpStyle       lipgloss.Style
+	infoPaneStyle   lipgloss.Style
+	typingPaneStyle lipgloss.Style
+	labelStyle      lipgloss.Style
+	valueStyle      lipgloss.Style
+	formLabelStyle  lipgloss.Style
+	errorStyle      lipgloss.Style
+
+	syntaxStyles map[syntaxClass]lipgloss.Style
+)
+
+func init() {
+	applyTheme(builtinThemes[defaultTheme])
+}
+
+// applyTheme rebuilds the styles from a theme's colours
+func applyTheme(t theme) {
 	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("170")).
-			MarginBottom(1)
+		Bold(true).
+		Foreground(lipgloss.Color(t.Title)).
+		MarginBottom(1)
 
 	correctStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("42"))
+		Foreground(lipgloss.Color(t.Correct))
 
 	correctedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("214")) // Orange/yellow for corrected chars
+		Foreground(lipgloss.Color(t.Corrected))
 
 	incorrectStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
-			Background(lipgloss.Color("52"))
+		Foreground(lipgloss.Color(t.Incorrect)).
+		Background(lipgloss.Color(t.IncorrectBg))
 
 	cursorStyle = lipgloss.NewStyle().
-			Underline(true).
-			UnderlineSpaces(true)
+		Underline(true).
+		UnderlineSpaces(true)
+	if t.Cursor != "" {
+		cursorStyle = cursorStyle.Foreground(lipgloss.Color(t.Cursor))
+	}
+
+	textStyle = lipgloss.NewStyle()
+	if t.Text != "" {
+		textStyle = textStyle.Foreground(lipgloss.Color(t.Text))
+	}
+
+	whitespaceStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Whitespace))
 
 	statsStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("86"))
+		Foreground(lipgloss.Color(t.Stats))
+
+	warningStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Warning))
 
 	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241")).
-			MarginTop(1)
+		Foreground(lipgloss.Color(t.Help)).
+		MarginTop(1)
 
 	infoPaneStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("238")).
-			Padding(1, 2)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(t.InfoPane)).
+		Padding(1, 2)
 
 	typingPaneStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("170")).
-			Padding(1, 2)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(t.TypingPane)).
+		Padding(1, 2)
 
 	labelStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
+		Foreground(lipgloss.Color(t.Label))
 
 	valueStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("255")).
-			Bold(true)
+		Foreground(lipgloss.Color(t.Value)).
+		Bold(true)
 
 	formLabelStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("86")).
-			Bold(true)
-)
+		Foreground(lipgloss.Color(t.FormLabel)).
+		Bold(true)
+
+	errorStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.Error))
 
+	syntaxStyles = map[syntaxClass]lipgloss.Style{syntaxNone: textStyle}
+	for class, color := range map[syntaxClass]string{
+		syntaxKeyword: t.Syntax.Keyword,
+		syntaxString:  t.Syntax.String,
+		syntaxComment: t.Syntax.Comment,
+		syntaxNumber:  t.Syntax.Number,
+	} {
+		syntaxStyles[class] = textStyle
+		if color != "" {
+			syntaxStyles[class] = lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const defaultTheme = "dark"
+
+// theme is a named colour palette. Colours are ANSI 256 numbers like "42" or
+// hex like "#859900"; empty means the terminal's own colour.
+type theme struct {
+	Base string `mapstructure:"base"` // Built-in theme a theme file starts from, default dark
+
+	Title       string `mapstructure:"title"`
+	Correct     string `mapstructure:"correct"`
+	Corrected   string `mapstructure:"corrected"` // Typed correctly after a mistake
+	Incorrect   string `mapstructure:"incorrect"`
+	IncorrectBg string `mapstructure:"incorrect_background"`
+	Cursor      string `mapstructure:"cursor"` // The untyped character under the cursor, which is also underlined
+	Text        string `mapstructure:"text"`   // Untyped code without a syntax class
+	Whitespace  string `mapstructure:"whitespace"`
+	Stats       string `mapstructure:"stats"`
+	Warning     string `mapstructure:"warning"` // Time running out
+	Label       string `mapstructure:"label"`
+	Value       string `mapstructure:"value"`
+	FormLabel   string `mapstructure:"form_label"`
+	Help        string `mapstructure:"help"`
+	Error       string `mapstructure:"error"`
+	InfoPane    string `mapstructure:"info_pane"` // Pane borders
+	TypingPane  string `mapstructure:"typing_pane"`
+
+	Syntax syntaxTheme `mapstructure:"syntax"`
+}
+
+// syntaxTheme colours untyped Go code by token class
+type syntaxTheme struct {
+	Keyword string `mapstructure:"keyword"`
+	String  string `mapstructure:"string"` // Also rune literals
+	Comment string `mapstructure:"comment"`
+	Number  string `mapstructure:"number"`
+}
+
+var builtinThemes = map[string]theme{
+	"dark": {
+		Title: "170", Correct: "42", Corrected: "214", Incorrect: "196", IncorrectBg: "52",
+		Whitespace: "240", Stats: "86", Warning: "214", Label: "241", Value: "255",
+		FormLabel: "86", Help: "241", Error: "196", InfoPane: "238", TypingPane: "170",
+		Syntax: syntaxTheme{Keyword: "111", String: "180", Comment: "244", Number: "176"},
+	},
+	"light": {
+		Title: "90", Correct: "28", Corrected: "130", Incorrect: "160", IncorrectBg: "224",
+		Text: "235", Whitespace: "250", Stats: "30", Warning: "130", Label: "244", Value: "232",
+		FormLabel: "30", Help: "244", Error: "160", InfoPane: "250", TypingPane: "90",
+		Syntax: syntaxTheme{Keyword: "25", String: "94", Comment: "245", Number: "127"},
+	},
+	"solarized": {
+		Title: "#d33682", Correct: "#859900", Corrected: "#b58900", Incorrect: "#dc322f", IncorrectBg: "#073642",
+		Text: "#839496", Whitespace: "#586e75", Stats: "#2aa198", Warning: "#cb4b16", Label: "#586e75", Value: "#93a1a1",
+		FormLabel: "#2aa198", Help: "#586e75", Error: "#dc322f", InfoPane: "#586e75", TypingPane: "#6c71c4",
+		Syntax: syntaxTheme{Keyword: "#268bd2", String: "#2aa198", Comment: "#586e75", Number: "#d33682"},
+	},
+	"high-contrast": {
+		Title: "15", Correct: "10", Corrected: "11", Incorrect: "15", IncorrectBg: "9",
+		Cursor: "11", Text: "15", Whitespace: "8", Stats: "14", Warning: "11", Label: "15", Value: "15",
+		FormLabel: "14", Help: "7", Error: "9", InfoPane: "15", TypingPane: "14",
+		Syntax: syntaxTheme{Keyword: "12", String: "13", Comment: "7", Number: "13"},
+	},
+}
+
+// themesDir holds theme files named <theme>.yaml
+func themesDir() string {
+	return filepath.Join(configDir(), "themes")
+}
+
+// checkThemeName rejects names that can't be used as a file name in the
+// themes directory
+func checkThemeName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid theme name %q", name)
+	}
+	return nil
+}
+
+func themePath(name string) string {
+	return filepath.Join(themesDir(), name+".yaml")
+}
+
+// themeNames lists the built-in themes and those in the themes directory
+func themeNames() []string {
+	names := slices.Collect(maps.Keys(builtinThemes))
+	files, _ := filepath.Glob(filepath.Join(themesDir(), "*.yaml"))
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// loadTheme reads a theme. A theme file only needs the colours it changes
+// from its base, and one named after a built-in theme customises it.
+func loadTheme(name string) (theme, error) {
+	if err := checkThemeName(name); err != nil {
+		return builtinThemes[defaultTheme], err
+	}
+	builtin, isBuiltin := builtinThemes[name]
+	data, err := os.ReadFile(themePath(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		if isBuiltin {
+			return builtin, nil
+		}
+		return builtinThemes[defaultTheme], fmt.Errorf("unknown theme %q, want one of %s", name, strings.Join(themeNames(), ", "))
+	}
+	if err != nil {
+		return builtinThemes[defaultTheme], err
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return builtinThemes[defaultTheme], fmt.Errorf("%s: %w", themePath(name), err)
+	}
+	base := v.GetString("base")
+	t, ok := builtinThemes[base]
+	switch {
+	case base == "" && isBuiltin:
+		t = builtin
+	case base == "":
+		t = builtinThemes[defaultTheme]
+	case !ok:
+		return builtinThemes[defaultTheme], fmt.Errorf("%s: unknown base theme %q", themePath(name), base)
+	}
+	if err := v.Unmarshal(&t); err != nil {
+		return builtinThemes[defaultTheme], fmt.Errorf("%s: %w", themePath(name), err)
+	}
+	return t, nil
+}
+
+// useTheme switches the styles to a theme, falling back to the default
+// theme if it can't be loaded
+func useTheme(name string) error {
+	t, err := loadTheme(name)
+	applyTheme(t)
+	return err
+}
+
+// syntaxClass is the kind of Go token a character belongs to
+type syntaxClass int
+
+const (
+	syntaxNone syntaxClass = iota
+	syntaxKeyword
+	syntaxString
+	syntaxComment
+	syntaxNumber
+)
+
+// syntaxClasses classifies each rune of Go source for highlighting, or
+// returns nil if it doesn't scan as Go
+func syntaxClasses(src []rune) []syntaxClass {
+	text := string(src)
+	// Byte offsets from the scanner to rune indexes
+	runeIndex := make([]int, len(text)+1)
+	i := 0
+	for offset := range text {
+		runeIndex[offset] = i
+		i++
+	}
+	runeIndex[len(text)] = i
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(text))
+	failed := false
+	var s scanner.Scanner
+	s.Init(file, []byte(text), func(token.Position, string) { failed = true }, scanner.ScanComments)
+
+	classes := make([]syntaxClass, len(src))
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		class := syntaxNone
+		switch {
+		case tok.IsKeyword():
+			class = syntaxKeyword
+		case tok == token.STRING || tok == token.CHAR:
+			class = syntaxString
+		case tok == token.COMMENT:
+			class = syntaxComment
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			class = syntaxNumber
+		}
+		if class == syntaxNone {
+			continue
+		}
+		start := file.Offset(pos)
+		end := min(start+len(lit), len(text))
+		for offset := start; offset < end; offset++ {
+			// Only offsets where a rune starts are set, the rest stay zero
+			if offset == 0 || runeIndex[offset] != 0 {
+				classes[runeIndex[offset]] = class
+			}
+		}
+	}
+	if failed {
+		return nil
+	}
+	return classes
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSyntaxClasses(t *testing.T) {
+	// Expected classes are written one letter per rune of the source: k for
+	// keywords, s for strings, c for comments, n for numbers and . for none
+	tests := []struct {
+		src  string
+		want string // Empty with fail set means nil
+		fail bool
+	}{
+		{src: "func f() {}", want: "kkkk......."},
+		{src: `s := "héllo"`, want: ".....sssssss"},
+		{src: "// ünïcode ✓", want: "cccccccccccc"},
+		{src: "x := 'ö' + 42", want: ".....sss...nn"},
+		{src: "é := 1.5i", want: ".....nnnn"},
+		{src: "/* ß */ return 0", want: "ccccccc.kkkkkk.n"},
+		{src: "x := `a\nü` // 日本", want: ".....sssss.ccccc"},
+		{src: "", want: ""},
+		{src: "`unterminated", fail: true},
+		{src: "# not Go", fail: true},
+	}
+	letters := map[syntaxClass]byte{
+		syntaxNone: '.', syntaxKeyword: 'k', syntaxString: 's', syntaxComment: 'c', syntaxNumber: 'n',
+	}
+	for _, tt := range tests {
+		got := syntaxClasses([]rune(tt.src))
+		if tt.fail {
+			if got != nil {
+				t.Errorf("syntaxClasses(%q) = %v, want nil", tt.src, got)
+			}
+			continue
+		}
+		var b strings.Builder
+		for _, class := range got {
+			b.WriteByte(letters[class])
+		}
+		if b.String() != tt.want {
+			t.Errorf("syntaxClasses(%q) = %s, want %s", tt.src, b.String(), tt.want)
+		}
+	}
+}
+
+func TestCheckThemeName(t *testing.T) {
+	for _, name := range []string{"dark", "my-theme", "solarized.v2"} {
+		if err := checkThemeName(name); err != nil {
+			t.Errorf("checkThemeName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", ".", "..", "../dark", `a\b`, "/etc/passwd"} {
+		if err := checkThemeName(name); err == nil {
+			t.Errorf("checkThemeName(%q) = nil, want an error", name)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"go/scanner"
+	"go/token"
+	"strings"
+	"unicode/utf8"
+)
+
+// goTokens scans text with go/scanner and returns the rune span of every
+// token, skipping the semicolons the scanner inserts automatically.
+// Scan errors are ignored so partially typed or invalid code still splits.
+func goTokens(text string) [][2]int {
+	src := []byte(text)
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var s scanner.Scanner
+	s.Init(file, src, func(token.Position, string) {}, scanner.ScanComments)
+
+	var spans [][2]int
+	byteOff, runeOff := 0, 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+
+		start := file.Offset(pos)
+		end := start + len(lit)
+		if lit == "" {
+			end = start + len(tok.String())
+		}
+		if end > len(src) {
+			end = len(src)
+		}
+
+		// Convert byte offsets to rune offsets incrementally
+		runeOff += utf8.RuneCount(src[byteOff:start])
+		runeStart := runeOff
+		runeOff += utf8.RuneCount(src[start:end])
+		byteOff = end
+
+		spans = append(spans, [2]int{runeStart, runeOff})
+	}
+	return spans
+}
+
+// previousWordStart returns the rune index where delete-previous-word should
+// cut the input. Words follow Go token boundaries, except inside comments
+// where whitespace separates words.
+func previousWordStart(input string) int {
+	runes := []rune(input)
+	end := len(runes)
+	for end > 0 && strings.ContainsRune(" \t\n", runes[end-1]) {
+		end--
+	}
+	if end == 0 {
+		return 0
+	}
+
+	trimmed := string(runes[:end])
+	spans := goTokens(trimmed)
+	if len(spans) == 0 {
+		return 0
+	}
+
+	last := spans[len(spans)-1]
+	lastToken := string(runes[last[0]:last[1]])
+	if strings.HasPrefix(lastToken, "//") || strings.HasPrefix(lastToken, "/*") {
+		for i := end - 1; i > last[0]; i-- {
+			if runes[i-1] == ' ' || runes[i-1] == '\t' {
+				return i
+			}
+		}
+	}
+	return last[0]
+}
+
+// lockedInputLength returns how many input runes are protected from deletion
+// because they complete target tokens that were typed correctly.
+func lockedInputLength(input, target string) int {
+	inputRunes := []rune(input)
+	targetRunes := []rune(target)
+	positions := alignInput(input, target)
+
+	// Length of the correctly typed prefix, in input and target runes
+	correct := 0
+	for correct < len(positions) && inputRunes[correct] == targetRunes[positions[correct]] {
+		correct++
+	}
+	if correct == 0 {
+		return 0
+	}
+	correctTarget := positions[correct-1] + 1
+
+	lockedTarget := 0
+	for _, span := range goTokens(target) {
+		if span[1] > correctTarget {
+			break
+		}
+		lockedTarget = span[1]
+	}
+
+	locked := 0
+	for locked < correct && positions[locked] < lockedTarget {
+		locked++
+	}
+	return locked
+}
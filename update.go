@@ -1,130 +1,108 @@
 package main
 
 import (
-	"strconv"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
-	switch msg := msg.(type) {
-	case tickMsg:
-		if m.started && !m.finished {
-			if m.config.MaxTimeLimit > 0 {
-				elapsed := time.Since(m.startTime)
-				maxDuration := time.Duration(m.config.MaxTimeLimit) * time.Second
-				if elapsed >= maxDuration {
-					m.finished = true
-					m.endTime = m.startTime.Add(maxDuration)
-					return m, nil
-				}
+	// Settings changed on disk apply whichever screen is showing
+	if _, ok := msg.(configChangedMsg); ok {
+		m.reloadConfig()
+		return m, m.watcher.next()
+	}
+
+	// The clock keeps running while the browser or settings are open
+	if _, ok := msg.(tickMsg); ok {
+		return m.updateTick()
+	}
+
+	if m.showingBrowser {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			if msg.Type == tea.KeyCtrlC {
+				return m, tea.Quit
 			}
-			// Continue ticking to update elapsed time
-			return m, tickCmd()
+			return m.updateBrowser(msg)
+		case tea.WindowSizeMsg:
+			m.width = msg.Width
+			m.height = msg.Height
+			m.browser.SetSize(msg.Width, msg.Height-2)
+			return m, nil
+		case snippetsIndexedMsg:
+			cmd = m.showSnippets(msg)
+			return m, cmd
 		}
-		return m, nil
+		// Filtering results and other list messages
+		m.browser, cmd = m.browser.Update(msg)
+		return m, cmd
+	}
+
+	if m.showingConfig {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			if msg.Type == tea.KeyCtrlC {
+				return m, tea.Quit
+			}
+			return m.updateSettings(msg)
+		case tea.WindowSizeMsg:
+			m.width = msg.Width
+			m.height = msg.Height
+			m.scrollSettings()
+			return m, nil
+		case folderCountMsg:
+			cmd = m.picker.setCount(msg)
+			return m, cmd
+		}
+		// Cursor blinks for the focused text input
+		key := settingsSchema[m.focusIndex].key
+		if ti, ok := m.settingInputs[key]; ok {
+			m.settingInputs[key], cmd = ti.Update(msg)
+		}
+		return m, cmd
+	}
 
+	if m.showingChallengePrompt {
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			if msg.Type == tea.KeyCtrlC {
+				return m, tea.Quit
+			}
+			return m.updateChallengePrompt(msg)
+		}
+	}
+
+	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.Type {
 		case tea.KeyCtrlC:
 			return m, tea.Quit
 
 		case tea.KeyEsc:
-			if m.showingConfig {
-				// Cancel settings without saving
-				m.showingConfig = false
-				return m, nil
-			}
 			return m, tea.Quit
 
 		case tea.KeyCtrlS:
-			// Toggle settings
-			if !m.showingConfig {
-				m.showingConfig = true
-				m.focusIndex = 0
-				m.configInputs[0].Focus()
-				for i := 1; i < len(m.configInputs); i++ {
-					m.configInputs[i].Blur()
-				}
-			} else {
-				// Cancel settings without saving
-				m.showingConfig = false
-			}
-			return m, nil
+			m.openSettings()
+			return m, textinput.Blink
 
 		case tea.KeyEnter:
-			if m.showingConfig {
-				// Save config
-				minLines, _ := strconv.Atoi(m.configInputs[1].Value())
-				maxLines, _ := strconv.Atoi(m.configInputs[2].Value())
-				maxTime, _ := strconv.Atoi(m.configInputs[3].Value())
-
-				m.config = config{
-					FolderPath:   m.configInputs[0].Value(),
-					MinLines:     minLines,
-					MaxLines:     maxLines,
-					MaxTimeLimit: maxTime,
-				}
-
-				if err := saveConfig(m.config); err != nil {
-					m.err = err
-				}
-
-				m.showingConfig = false
-
-				// Load new function with new settings
-				if m.targetText != "" {
-					funcText, filePath, err := loadRandomFunction(m.config)
-					if err != nil {
-						m.err = err
-					} else {
-						m.targetText = funcText
-						m.currentFile = filePath
-						m.currentInput = ""
-						m.started = false
-						m.finished = false
-						m.correctChars = 0
-						m.incorrectChars = 0
-						m.errorPositions = make(map[int]bool)
-					}
-				}
-				return m, nil
-			}
-
 			if m.targetText == "" {
 				// Initial load
-				funcText, filePath, err := loadRandomFunction(m.config)
-				if err != nil {
-					m.err = err
-					return m, nil
-				}
-				m.targetText = funcText
-				m.currentFile = filePath
-				m.currentInput = ""
-				m.correctChars = 0
-				m.incorrectChars = 0
-				m.errorPositions = make(map[int]bool)
-				return m, nil
+				cmd = m.loadNextFunction()
+				return m, cmd
 			}
 
 			if m.finished {
 				// Reset for another round
-				funcText, filePath, err := loadRandomFunction(m.config)
-				if err != nil {
-					m.err = err
-					return m, nil
-				}
-				m.targetText = funcText
-				m.currentFile = filePath
-				m.currentInput = ""
-				m.started = false
-				m.finished = false
-				m.correctChars = 0
-				m.incorrectChars = 0
-				m.errorPositions = make(map[int]bool)
+				cmd = m.loadNextFunction()
+				return m, cmd
+			}
+
+			if m.studying {
+				m.studying = false
 				return m, nil
 			}
 
@@ -156,6 +134,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				// Now targetPos is where we are in the target
 				if targetPos < len(targetRunes) && targetRunes[targetPos] == '\n' {
+					if m.screenKeystroke(msg) {
+						return m, nil
+					}
 					// Just add newline - rendering will skip the leading whitespace automatically
 					m.currentInput += "\n"
 					// Count the newline as correct
@@ -164,48 +145,33 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case tea.KeyCtrlR:
-			if !m.showingConfig && m.targetText != "" {
-				// Reload with a new function
-				funcText, filePath, err := loadRandomFunction(m.config)
-				if err != nil {
-					m.err = err
-					return m, nil
-				}
-				m.targetText = funcText
-				m.currentFile = filePath
-				m.currentInput = ""
-				m.started = false
-				m.finished = false
-				m.correctChars = 0
-				m.incorrectChars = 0
-				m.errorPositions = make(map[int]bool)
+		case tea.KeyCtrlO:
+			cmd = m.openBrowser()
+			return m, cmd
+
+		case tea.KeyCtrlX:
+			if m.targetText != "" {
+				m.shareChallenge()
 				return m, nil
 			}
 
-		case tea.KeyTab, tea.KeyShiftTab:
-			if m.showingConfig {
-				// Navigate between config inputs
-				if msg.Type == tea.KeyTab {
-					m.focusIndex++
-					if m.focusIndex >= len(m.configInputs) {
-						m.focusIndex = 0
-					}
-				} else {
-					m.focusIndex--
-					if m.focusIndex < 0 {
-						m.focusIndex = len(m.configInputs) - 1
-					}
-				}
+		case tea.KeyCtrlL:
+			m.openChallengePrompt()
+			return m, textinput.Blink
 
-				for i := range m.configInputs {
-					if i == m.focusIndex {
-						m.configInputs[i].Focus()
-					} else {
-						m.configInputs[i].Blur()
-					}
-				}
-				return m, nil
+		case tea.KeyCtrlU:
+			cmd = m.nextProfile()
+			return m, cmd
+
+		case tea.KeyCtrlN:
+			cmd = m.nextPreset()
+			return m, cmd
+
+		case tea.KeyCtrlR:
+			if m.targetText != "" {
+				// Reload with a new function
+				cmd = m.loadNextFunction()
+				return m, cmd
 			}
 		}
 
@@ -219,43 +185,77 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	if m.showingConfig {
-		m.configInputs[m.focusIndex], cmd = m.configInputs[m.focusIndex].Update(msg)
-		return m, cmd
-	}
-
 	if !m.finished && m.targetText != "" {
+		// Any key ends the memory mode study period without being typed
+		if _, ok := msg.(tea.KeyMsg); ok && m.studying {
+			m.studying = false
+			return m, nil
+		}
+
 		// Handle typing manually instead of using textInput
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
 			switch msg.Type {
-			case tea.KeyBackspace:
-				if len(m.currentInput) > 0 {
-					m.currentInput = m.currentInput[:len(m.currentInput)-1]
+			case tea.KeyCtrlP:
+				if m.config.MemoryMode {
+					m.peek()
+				}
+			case tea.KeyBackspace, tea.KeyCtrlW, tea.KeyCtrlH:
+				inputRunes := []rune(m.currentInput)
+				cut := len(inputRunes) - 1
+				// Ctrl+W, Alt+Backspace and Ctrl+Backspace delete a word.
+				// Terminals send Ctrl+Backspace as Ctrl+H, but some send that
+				// for Backspace itself, so it only deletes a word once
+				// Backspace has arrived as DEL.
+				if msg.Type == tea.KeyBackspace && !msg.Alt {
+					m.backspaceIsDEL = true
+				}
+				if msg.Type == tea.KeyCtrlW || msg.Alt || msg.Type == tea.KeyCtrlH && m.backspaceIsDEL {
+					cut = previousWordStart(m.currentInput)
+				}
+				if m.config.LockCompletedTokens {
+					cut = max(cut, lockedInputLength(m.currentInput, m.targetText))
+				}
+				if cut >= 0 && cut < len(inputRunes) {
+					m.currentInput = string(inputRunes[:cut])
 				}
 			case tea.KeySpace:
+				if m.screenKeystroke(msg) {
+					return m, nil
+				}
 				// Get current position in target
 				pos := getCurrentPosition(m.currentInput, m.targetText)
 				// Check if this character is correct before adding
-				if isCharacterCorrect(m.currentInput, m.targetText, " ") {
+				correct := isCharacterCorrect(m.currentInput, m.targetText, " ")
+				m.currentInput += " "
+				if correct {
 					m.correctChars++
 				} else {
 					m.incorrectChars++
 					m.errorPositions[pos] = true
+					if m.handleMistake() {
+						return m, nil
+					}
 				}
-				m.currentInput += " "
 			case tea.KeyRunes:
+				if m.screenKeystroke(msg) {
+					return m, nil
+				}
 				// Get current position in target
 				pos := getCurrentPosition(m.currentInput, m.targetText)
 				// Check if this character is correct before adding
 				char := string(msg.Runes)
-				if isCharacterCorrect(m.currentInput, m.targetText, char) {
+				correct := isCharacterCorrect(m.currentInput, m.targetText, char)
+				m.currentInput += char
+				if correct {
 					m.correctChars++
 				} else {
 					m.incorrectChars++
 					m.errorPositions[pos] = true
+					if m.handleMistake() {
+						return m, nil
+					}
 				}
-				m.currentInput += char
 			}
 		}
 
@@ -269,8 +269,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Check if finished (compare without leading whitespace)
 		if normalizeText(m.currentInput) == normalizeText(m.targetText) {
-			m.finished = true
-			m.endTime = time.Now()
+			m.finish(outcomeCompleted, time.Now())
 		}
 
 		return m, cmd
@@ -279,3 +278,26 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// updateTick advances the study countdown and enforces the time limit
+func (m model) updateTick() (tea.Model, tea.Cmd) {
+	if m.studying && m.config.StudySeconds > 0 {
+		if m.studyRemaining() == 0 {
+			m.studying = false
+			return m, nil
+		}
+		return m, tickCmd()
+	}
+	if m.started && !m.finished {
+		if m.config.MaxTimeLimit > 0 {
+			elapsed := time.Since(m.startTime)
+			maxDuration := time.Duration(m.config.MaxTimeLimit) * time.Second
+			if elapsed >= maxDuration {
+				m.finish(outcomeTimeout, m.startTime.Add(maxDuration))
+				return m, nil
+			}
+		}
+		// Continue ticking to update elapsed time
+		return m, tickCmd()
+	}
+	return m, nil
+}
@@ -10,10 +10,22 @@ import (
 )
 
 func (m model) View() string {
+	if m.showingConfig && m.showingPicker {
+		return m.renderFolderPicker()
+	}
+
 	if m.showingConfig {
 		return m.renderConfigView()
 	}
 
+	if m.showingBrowser {
+		return m.browser.View()
+	}
+
+	if m.showingChallengePrompt {
+		return m.renderChallengePrompt()
+	}
+
 	if m.err != nil {
 		return fmt.Sprintf("\n%s\n\nError: %v\n\n%s\n",
 			titleStyle.Render("⚡ Typing Vibes"),
@@ -23,10 +35,11 @@ func (m model) View() string {
 
 	if m.targetText == "" {
 		return fmt.Sprintf(
-			"%s\n\n%s\n\n%s\n",
+			"%s\n\n%s\n\n%s%s\n",
 			titleStyle.Render("⚡ Typing Vibes"),
 			"Press Enter to load a function from your configured folder.",
-			helpStyle.Render("Ctrl+S for settings • Esc to quit"),
+			m.renderConfigErr(),
+			helpStyle.Render("Ctrl+O to browse functions • Ctrl+L to play a challenge code • Ctrl+N to switch preset • Ctrl+U to switch profile • Ctrl+S for settings • Esc to quit"),
 		)
 	}
 
@@ -52,54 +65,53 @@ func (m model) View() string {
 	b.WriteString(panes)
 	b.WriteString("\n\n")
 
+	if m.status != "" {
+		b.WriteString(labelStyle.Render(m.status))
+		b.WriteString("\n")
+	}
+	b.WriteString(m.renderConfigErr())
+
 	// Help text at bottom
-	if m.finished {
-		b.WriteString(helpStyle.Render("Enter for new test • Ctrl+R for new function • Ctrl+S for settings • Esc to quit"))
+	if m.studying {
+		if m.config.StudySeconds > 0 {
+			b.WriteString(helpStyle.Render(fmt.Sprintf("📖 Study the function • %.0fs left • Press any key to start typing now", m.studyRemaining().Seconds())))
+		} else {
+			b.WriteString(helpStyle.Render("📖 Study the function • Press any key when you're ready to type it from memory"))
+		}
+	} else if m.finished {
+		b.WriteString(helpStyle.Render("Enter for new test • Ctrl+R for new function • Ctrl+N to switch preset • Ctrl+U to switch profile • Ctrl+X to share as a challenge • Ctrl+O to browse • Ctrl+S for settings • Esc to quit"))
 	} else {
-		b.WriteString(helpStyle.Render("Ctrl+R for new function • Ctrl+S for settings • Esc to quit"))
+		if m.config.MemoryMode {
+			b.WriteString(helpStyle.Render("Ctrl+P to peek at the current line • Ctrl+R for new function • Ctrl+S for settings • Esc to quit"))
+		} else {
+			b.WriteString(helpStyle.Render("Ctrl+R for new function • Ctrl+O to browse • Ctrl+S for settings • Esc to quit"))
+		}
 	}
 
 	return b.String()
 }
 
-func (m model) renderConfigView() string {
-	var b strings.Builder
-
-	b.WriteString(titleStyle.Render("⚙️  Settings"))
-	b.WriteString("\n\n")
-
-	b.WriteString(formLabelStyle.Render("Folder Path:"))
-	b.WriteString("\n")
-	b.WriteString(m.configInputs[0].View())
-	b.WriteString("\n\n")
-
-	b.WriteString(formLabelStyle.Render("Minimum Lines:"))
-	b.WriteString("\n")
-	b.WriteString(m.configInputs[1].View())
-	b.WriteString("\n\n")
-
-	b.WriteString(formLabelStyle.Render("Maximum Lines:"))
-	b.WriteString("\n")
-	b.WriteString(m.configInputs[2].View())
-	b.WriteString("\n\n")
-
-	b.WriteString(formLabelStyle.Render("Max Time Limit (seconds, 0 = no limit):"))
-	b.WriteString("\n")
-	b.WriteString(m.configInputs[3].View())
-	b.WriteString("\n\n")
-
-	b.WriteString(helpStyle.Render("Tab/Shift+Tab to navigate • Enter to save • Ctrl+S or Esc to cancel"))
-
-	return b.String()
-}
-
 func (m model) renderInfoPane() string {
 	var b strings.Builder
 
 	// Directory info
-	b.WriteString(labelStyle.Render("📁 Directory:"))
-	b.WriteString("\n")
-	b.WriteString(valueStyle.Render(m.config.FolderPath))
+	if len(m.config.Corpora) > 0 {
+		b.WriteString(labelStyle.Render("📚 Corpora:"))
+		b.WriteString("\n")
+		b.WriteString(valueStyle.Render(strings.Join(corporaSummary(m.config.Corpora), "\n")))
+	} else if m.config.Source == sourcePackages {
+		b.WriteString(labelStyle.Render("📦 Packages:"))
+		b.WriteString("\n")
+		b.WriteString(valueStyle.Render(strings.Join(m.config.Packages, "\n")))
+	} else if m.config.Source == sourceEmbedded {
+		b.WriteString(labelStyle.Render("📦 Corpus:"))
+		b.WriteString("\n")
+		b.WriteString(valueStyle.Render("built-in"))
+	} else {
+		b.WriteString(labelStyle.Render("📁 Directory:"))
+		b.WriteString("\n")
+		b.WriteString(valueStyle.Render(m.config.FolderPath))
+	}
 	b.WriteString("\n\n")
 
 	// File info
@@ -118,6 +130,48 @@ func (m model) renderInfoPane() string {
 	b.WriteString(valueStyle.Render(displayPath))
 	b.WriteString("\n\n")
 
+	if m.dailyDate != "" {
+		b.WriteString(labelStyle.Render("📅 Daily Challenge:"))
+		b.WriteString("\n")
+		b.WriteString(valueStyle.Render(m.dailyDate))
+		b.WriteString("\n\n")
+	}
+
+	if name := profileName(); name != "" {
+		b.WriteString(labelStyle.Render("👤 Profile:"))
+		b.WriteString("\n")
+		b.WriteString(valueStyle.Render(name))
+		b.WriteString("\n\n")
+	}
+
+	if m.config.Preset != "" {
+		b.WriteString(labelStyle.Render("🎛  Preset:"))
+		b.WriteString("\n")
+		b.WriteString(valueStyle.Render(m.config.Preset))
+		b.WriteString("\n\n")
+	}
+
+	if m.config.RepoConfig != "" {
+		b.WriteString(labelStyle.Render("📘 Repo Settings:"))
+		b.WriteString("\n")
+		b.WriteString(valueStyle.Render(contractHome(m.config.RepoConfig)))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(labelStyle.Render("🎮 Mode:"))
+	b.WriteString("\n")
+	b.WriteString(valueStyle.Render(m.config.Mode))
+	if m.config.BlindMode {
+		b.WriteString(valueStyle.Render(" (blind)"))
+	}
+	if m.config.MemoryMode {
+		b.WriteString(valueStyle.Render(" (memory)"))
+	}
+	if m.restarts > 0 {
+		b.WriteString(labelStyle.Render(fmt.Sprintf(" (%d restarts)", m.restarts)))
+	}
+	b.WriteString("\n\n")
+
 	b.WriteString("─────────────────────────────────")
 	b.WriteString("\n\n")
 
@@ -144,13 +198,13 @@ func (m model) renderInfoPane() string {
 		}
 		b.WriteString(labelStyle.Render("⏱️  Time Remaining:"))
 		b.WriteString("\n")
-		timeColor := lipgloss.Color("86")
+		timeStyle := statsStyle
 		if remaining < 10*time.Second {
-			timeColor = lipgloss.Color("196")
+			timeStyle = errorStyle
 		} else if remaining < 20*time.Second {
-			timeColor = lipgloss.Color("214")
+			timeStyle = warningStyle
 		}
-		b.WriteString(lipgloss.NewStyle().Foreground(timeColor).Bold(true).Render(fmt.Sprintf("%.1fs", remaining.Seconds())))
+		b.WriteString(timeStyle.Bold(true).Render(fmt.Sprintf("%.1fs", remaining.Seconds())))
 		b.WriteString("\n\n")
 	}
 
@@ -169,6 +223,13 @@ func (m model) renderInfoPane() string {
 		b.WriteString("\n\n")
 	}
 
+	if best := m.bests[m.categoryLabel()]; best > 0 {
+		b.WriteString(labelStyle.Render("🏆 Personal Best:"))
+		b.WriteString("\n")
+		b.WriteString(statsStyle.Render(fmt.Sprintf("%.1f", best)))
+		b.WriteString("\n\n")
+	}
+
 	// Progress
 	b.WriteString(labelStyle.Render("📊 Progress:"))
 	b.WriteString("\n")
@@ -178,8 +239,8 @@ func (m model) renderInfoPane() string {
 	b.WriteString(statsStyle.Render(fmt.Sprintf("%d/%d (%.1f%%)", len(normalizedInput), len(normalizedTarget), progress)))
 	b.WriteString("\n\n")
 
-	// Live accuracy (shown during typing and when finished)
-	if m.started {
+	// Live accuracy (shown during typing and when finished, hidden while blind)
+	if m.started && (!m.config.BlindMode || m.finished) {
 		accuracy := calculateAccuracyFromCounters(m.correctChars, m.incorrectChars)
 		b.WriteString(labelStyle.Render("✓ Accuracy:"))
 		b.WriteString("\n")
@@ -187,6 +248,25 @@ func (m model) renderInfoPane() string {
 		b.WriteString("\n")
 	}
 
+	if m.config.MemoryMode && !m.studying {
+		b.WriteString(labelStyle.Render("👀 Peeked:"))
+		b.WriteString("\n")
+		b.WriteString(statsStyle.Render(fmt.Sprintf("%.1f%%", m.peekedPercent())))
+		b.WriteString("\n")
+	}
+
+	if m.invalidReason != "" {
+		b.WriteString("\n")
+		b.WriteString(incorrectStyle.Render("⚠ Invalid: " + m.invalidReason))
+		b.WriteString("\n")
+	}
+
+	if m.outcome == outcomeFailed {
+		b.WriteString("\n")
+		b.WriteString(incorrectStyle.Render("💀 Failed on first mistake"))
+		b.WriteString("\n")
+	}
+
 	return b.String()
 }
 
@@ -236,6 +316,23 @@ func (m model) renderTypingPane(width int) string {
 
 	cursorTargetPos = tempTargetPos
 
+	// Blind mode hides all correctness feedback until the round is over
+	blind := m.config.BlindMode && !m.finished
+	// Memory mode hides untyped characters once the study period is over
+	hidden := m.config.MemoryMode && !m.studying && !m.finished
+
+	// Limited lookahead shows only the cursor line and the next N lines,
+	// collapsing the lines already typed
+	firstLine, lastLine := 0, len(targetLines)-1
+	if m.config.LookaheadLines >= 0 && !m.finished {
+		firstLine = strings.Count(string(targetRunes[:cursorTargetPos]), "\n")
+		lastLine = min(firstLine+m.config.LookaheadLines, len(targetLines)-1)
+	}
+	if firstLine > 0 {
+		result.WriteString(labelStyle.Render(fmt.Sprintf("✓ %d lines typed", firstLine)))
+		result.WriteString("\n")
+	}
+
 	// Process each line
 	for lineIdx, targetLine := range targetLines {
 		var topLine strings.Builder
@@ -251,8 +348,8 @@ func (m model) renderTypingPane(width int) string {
 
 			if isLeadingWhitespace {
 				// Leading whitespace
-				topLine.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(string(targetChar)))
-				bottomLine.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(string(targetChar)))
+				topLine.WriteString(whitespaceStyle.Render(string(targetChar)))
+				bottomLine.WriteString(whitespaceStyle.Render(string(targetChar)))
 				targetPos++
 			} else {
 				lineAtStart = false
@@ -261,7 +358,7 @@ func (m model) renderTypingPane(width int) string {
 					inputChar := inputRunes[inputPos]
 
 					// Top line: ONLY show incorrect inputs
-					if inputChar == targetChar {
+					if inputChar == targetChar || blind {
 						topLine.WriteString(" ") // Correct - show space
 					} else {
 						topLine.WriteString(incorrectStyle.Render(string(inputChar))) // Wrong - show in red
@@ -279,9 +376,12 @@ func (m model) renderTypingPane(width int) string {
 					} else {
 						targetStyle = incorrectStyle // Red for current error
 					}
+					if blind {
+						targetStyle = syntaxStyle(m.syntax, targetPos)
+					}
 
 					if isCursor {
-						targetStyle = targetStyle.Underline(true).UnderlineSpaces(true)
+						targetStyle = targetStyle.Inherit(cursorStyle)
 					}
 
 					bottomLine.WriteString(targetStyle.Render(string(targetChar)))
@@ -290,24 +390,32 @@ func (m model) renderTypingPane(width int) string {
 					// Not typed yet
 					topLine.WriteString(" ")
 
-					style := lipgloss.NewStyle()
+					style := syntaxStyle(m.syntax, targetPos)
 					if isCursor {
-						style = style.Underline(true).UnderlineSpaces(true)
+						style = cursorStyle.Inherit(style)
 					}
-					bottomLine.WriteString(style.Render(string(targetChar)))
+					displayChar := string(targetChar)
+					if hidden && !m.peeked[targetPos] {
+						displayChar = " "
+					}
+					bottomLine.WriteString(style.Render(displayChar))
 				}
 				targetPos++
 			}
 		}
 
-		// Add the line pair to result
-		result.WriteString(topLine.String())
-		result.WriteString("\n")
-		result.WriteString(bottomLine.String())
+		// Add the line pair to result if it's inside the lookahead window
+		if lineIdx >= firstLine && lineIdx <= lastLine {
+			result.WriteString(topLine.String())
+			result.WriteString("\n")
+			result.WriteString(bottomLine.String())
+			if lineIdx < lastLine {
+				result.WriteString("\n")
+			}
+		}
 
-		// Add newline between lines (not after last line)
+		// Account for the newline between lines (not after last line)
 		if lineIdx < len(targetLines)-1 {
-			result.WriteString("\n")
 			targetPos++ // Account for the \n character in target
 
 			// Skip the newline in input if we've typed it
@@ -317,6 +425,30 @@ func (m model) renderTypingPane(width int) string {
 		}
 	}
 
+	if hiddenAfter := len(targetLines) - 1 - lastLine; hiddenAfter > 0 {
+		result.WriteString("\n")
+		result.WriteString(labelStyle.Render(fmt.Sprintf("… %d more lines", hiddenAfter)))
+	}
+
 	return result.String()
 }
 
+// renderConfigErr explains why a config file couldn't be read or the theme
+// couldn't be used, until it's fixed
+func (m model) renderConfigErr() string {
+	if m.configErr != nil {
+		return errorStyle.Render(fmt.Sprintf("⚠ Config error: %v. Settings reload once it's fixed.", m.configErr)) + "\n"
+	}
+	if m.config.ThemeErr != nil {
+		return warningStyle.Render(fmt.Sprintf("⚠ %v, using the %s theme", m.config.ThemeErr, defaultTheme)) + "\n"
+	}
+	return ""
+}
+
+// syntaxStyle is the theme's style for the token at a target position
+func syntaxStyle(syntax []syntaxClass, pos int) lipgloss.Style {
+	if pos < len(syntax) {
+		return syntaxStyles[syntax[pos]]
+	}
+	return textStyle
+}